@@ -3,6 +3,7 @@ package certutils
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -18,41 +19,98 @@ type ACM struct {
 
 type ACMDescription struct {
 	arn                     string
+	region                  string
 	nameTag                 string
+	tags                    []Tag
 	status                  string
+	certType                string
+	keyAlgorithm            string
 	inUseBy                 []string
 	notAfter                time.Time
 	domainName              string
 	subjectAlternativeNames []string
 }
 
+type ACMListFilter struct {
+	ExpiresWithin time.Duration
+	Domain        string
+	Tags          []Tag
+	InUse         bool
+	Unused        bool
+	Type          string
+}
+
 func NewACM(sess *session.Session) *ACM {
 	return &ACM{
 		client: acm.New(sess),
 	}
 }
 
-func createACMImportCertificateInput(cert, chain, pkey []byte) *acm.ImportCertificateInput {
-	return &acm.ImportCertificateInput{
-		Certificate:      cert,
-		CertificateChain: chain,
-		PrivateKey:       pkey,
+func createACMImportCertificateInput(arn string, cert, chain, pkey []byte) *acm.ImportCertificateInput {
+	input := &acm.ImportCertificateInput{
+		Certificate: cert,
+		PrivateKey:  pkey,
+	}
+
+	if arn != "" {
+		input.SetCertificateArn(arn)
 	}
+
+	if len(chain) > 0 {
+		input.SetCertificateChain(chain)
+	}
+
+	return input
 }
 
 func (a *ACM) Import(cert, chain, pkey []byte) (string, string, error) {
-	out, err := a.client.ImportCertificate(createACMImportCertificateInput(cert, chain, pkey))
+	out, err := a.client.ImportCertificate(createACMImportCertificateInput("", cert, chain, pkey))
+	if err != nil {
+		return "", "", err
+	}
+
+	return *out.CertificateArn, fmt.Sprintf("Imported %s", *out.CertificateArn), nil
+}
+
+func (a *ACM) Reimport(arn string, cert, chain, pkey []byte) (string, error) {
+	_, err := a.client.ImportCertificate(createACMImportCertificateInput(arn, cert, chain, pkey))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Reimported %s", arn), nil
+}
+
+func createACMGetCertificateInput(arn string) *acm.GetCertificateInput {
+	input := &acm.GetCertificateInput{}
+
+	input.SetCertificateArn(arn)
+
+	return input
+}
+
+func (a *ACM) Get(arn string) ([]byte, []byte, error) {
+	out, err := a.client.GetCertificate(createACMGetCertificateInput(arn))
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
 
-	return *out.CertificateArn, fmt.Sprintf("Imported %s", *out.CertificateArn), err
+	return []byte(aws.StringValue(out.Certificate)), []byte(aws.StringValue(out.CertificateChain)), nil
 }
 
-func createACMListCertificatesInput(statuses []string, maxItems int64, nextToken string) *acm.ListCertificatesInput {
+func createACMListCertificatesInput(statuses, keyTypes []string, maxItems int64, nextToken string) *acm.ListCertificatesInput {
 	linput := &acm.ListCertificatesInput{}
 
 	if len(statuses) > 0 {
 		linput.SetCertificateStatuses(aws.StringSlice(statuses))
 	}
 
+	if len(keyTypes) > 0 {
+		filters := &acm.Filters{}
+		filters.SetKeyTypes(aws.StringSlice(keyTypes))
+		linput.SetIncludes(filters)
+	}
+
 	if maxItems > 0 {
 		linput.SetMaxItems(maxItems)
 	}
@@ -71,26 +129,50 @@ func (a *ACM) listTags(arn string) (*acm.ListTagsForCertificateOutput, error) {
 	return a.client.ListTagsForCertificate(input)
 }
 
-func (a *ACM) getNameTag(arn string) (string, error) {
+func (a *ACM) ListTags(arn string) ([]Tag, error) {
 	out, err := a.listTags(arn)
 	if err != nil {
-		return "", err
+		return []Tag{}, err
 	}
 
+	tags := make([]Tag, 0, len(out.Tags))
 	for _, tag := range out.Tags {
-		if strings.ToLower(aws.StringValue(tag.Key)) == "name" {
-			return *tag.Value, nil
+		tags = append(tags, Tag{
+			Key:   aws.StringValue(tag.Key),
+			Value: aws.StringValue(tag.Value),
+		})
+	}
+
+	return tags, nil
+}
+
+func getNameTag(tags []Tag) string {
+	for _, tag := range tags {
+		if strings.ToLower(tag.Key) == "name" {
+			return tag.Value
 		}
 	}
 
-	return "", fmt.Errorf("Name tag not found")
+	return ""
 }
 
-func (a *ACM) List(statuses string, maxItems int64, nextToken string) ([]ACMDescription, error) {
-	cout, err := a.client.ListCertificates(createACMListCertificatesInput(SplitStatuses(statuses), maxItems, nextToken))
+func (a *ACM) List(statuses, keyTypes string, maxItems int64, nextToken string) ([]ACMDescription, error) {
+	summaries := make([]*acm.CertificateSummary, 0)
+	err := a.client.ListCertificatesPages(createACMListCertificatesInput(SplitStatuses(statuses), SplitKeyTypes(keyTypes), maxItems, nextToken),
+		func(out *acm.ListCertificatesOutput, lastPage bool) bool {
+			summaries = append(summaries, out.CertificateSummaryList...)
+			return maxItems <= 0 || int64(len(summaries)) < maxItems
+		})
+	if err != nil {
+		return []ACMDescription{}, err
+	}
+
+	if maxItems > 0 && int64(len(summaries)) > maxItems {
+		summaries = summaries[:maxItems]
+	}
 
-	descs := make([]ACMDescription, 0, len(cout.CertificateSummaryList))
-	for _, summary := range cout.CertificateSummaryList {
+	descs := make([]ACMDescription, 0, len(summaries))
+	for _, summary := range summaries {
 		dcout, err := a.client.DescribeCertificate(&acm.DescribeCertificateInput{
 			CertificateArn: summary.CertificateArn,
 		})
@@ -99,12 +181,18 @@ func (a *ACM) List(statuses string, maxItems int64, nextToken string) ([]ACMDesc
 		}
 
 		cert := dcout.Certificate
-		nameTag, _ := a.getNameTag(*cert.CertificateArn)
+		tags, err := a.ListTags(*cert.CertificateArn)
+		if err != nil {
+			return []ACMDescription{}, err
+		}
 
 		desc := ACMDescription{
 			arn:                     *cert.CertificateArn,
-			nameTag:                 nameTag,
+			nameTag:                 getNameTag(tags),
+			tags:                    tags,
 			status:                  *cert.Status,
+			certType:                aws.StringValue(cert.Type),
+			keyAlgorithm:            aws.StringValue(cert.KeyAlgorithm),
 			inUseBy:                 aws.StringValueSlice(cert.InUseBy),
 			notAfter:                aws.TimeValue(cert.NotAfter),
 			domainName:              *cert.DomainName,
@@ -114,11 +202,27 @@ func (a *ACM) List(statuses string, maxItems int64, nextToken string) ([]ACMDesc
 		descs = append(descs, desc)
 	}
 
-	return descs, err
+	return descs, nil
+}
+
+func (a *ACM) ListArns(statuses string, filter ACMListFilter) ([]string, error) {
+	descs, err := a.List(statuses, "", 0, "")
+	if err != nil {
+		return []string{}, err
+	}
+
+	descs = FilterACMDescriptions(descs, filter)
+
+	arns := make([]string, 0, len(descs))
+	for _, desc := range descs {
+		arns = append(arns, desc.arn)
+	}
+
+	return arns, nil
 }
 
 func (a *ACM) ListDeleteTargets(statuses string, maxItems int64, nextToken string) ([]string, map[string]string, error) {
-	descs, err := a.List(statuses, maxItems, nextToken)
+	descs, err := a.List(statuses, "", maxItems, nextToken)
 	if err != nil {
 		return []string{}, map[string]string{}, err
 	}
@@ -134,6 +238,109 @@ func (a *ACM) ListDeleteTargets(statuses string, maxItems int64, nextToken strin
 	return arns, targets, err
 }
 
+func (desc ACMDescription) names() []string {
+	names := []string{desc.domainName}
+	for _, name := range desc.subjectAlternativeNames {
+		if name == desc.domainName {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func (desc ACMDescription) matchDomain(domain string) bool {
+	for _, name := range desc.names() {
+		if MatchDomain(domain, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (desc ACMDescription) hasTags(tags []Tag) bool {
+	for _, t := range tags {
+		found := false
+		for _, dt := range desc.tags {
+			if dt.Key == t.Key && (t.Value == "" || dt.Value == t.Value) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func ACMCertificateType(t string) string {
+	return strings.ToUpper(strings.Replace(t, "-", "_", -1))
+}
+
+func FilterACMDescriptions(descs []ACMDescription, filter ACMListFilter) []ACMDescription {
+	filtered := make([]ACMDescription, 0, len(descs))
+	for _, desc := range descs {
+		if filter.ExpiresWithin > 0 && !ExpiresWithin(desc.notAfter, filter.ExpiresWithin) {
+			continue
+		}
+
+		if filter.Domain != "" && !desc.matchDomain(filter.Domain) {
+			continue
+		}
+
+		if len(filter.Tags) > 0 && !desc.hasTags(filter.Tags) {
+			continue
+		}
+
+		if filter.InUse && len(desc.inUseBy) == 0 {
+			continue
+		}
+
+		if filter.Unused && len(desc.inUseBy) > 0 {
+			continue
+		}
+
+		if filter.Type != "" && desc.certType != ACMCertificateType(filter.Type) {
+			continue
+		}
+
+		filtered = append(filtered, desc)
+	}
+
+	return filtered
+}
+
+func SortACMDescriptions(descs []ACMDescription, sortBy string) error {
+	switch sortBy {
+	case "":
+	case "expiry":
+		sort.SliceStable(descs, func(i, j int) bool {
+			return descs[i].notAfter.Before(descs[j].notAfter)
+		})
+	case "domain":
+		sort.SliceStable(descs, func(i, j int) bool {
+			return descs[i].domainName < descs[j].domainName
+		})
+	case "name":
+		sort.SliceStable(descs, func(i, j int) bool {
+			return descs[i].nameTag < descs[j].nameTag
+		})
+	case "region":
+		sort.SliceStable(descs, func(i, j int) bool {
+			return descs[i].region < descs[j].region
+		})
+	default:
+		return fmt.Errorf("Invalid sort key %s. Supported keys are expiry, domain, name and region", sortBy)
+	}
+
+	return nil
+}
+
 func toACMTags(tags []Tag) []*acm.Tag {
 	if len(tags) <= 0 {
 		return []*acm.Tag{}
@@ -167,6 +374,46 @@ func (a *ACM) AddTags(arn string, tags []Tag) error {
 	return err
 }
 
+func createACMRemoveTagsFromCertificateInput(arn string, tags []Tag) *acm.RemoveTagsFromCertificateInput {
+	acmTags := make([]*acm.Tag, 0, len(tags))
+	for _, t := range tags {
+		acmTag := &acm.Tag{}
+		acmTag.SetKey(t.Key)
+		if t.Value != "" {
+			acmTag.SetValue(t.Value)
+		}
+		acmTags = append(acmTags, acmTag)
+	}
+
+	rinput := &acm.RemoveTagsFromCertificateInput{}
+	rinput.SetCertificateArn(arn)
+	rinput.SetTags(acmTags)
+
+	return rinput
+}
+
+func (a *ACM) RemoveTags(arn string, tags []Tag) error {
+	_, err := a.client.RemoveTagsFromCertificate(createACMRemoveTagsFromCertificateInput(arn, tags))
+
+	return err
+}
+
+func (a *ACM) ReplaceTags(arn string, tags []Tag) error {
+	return replaceTags(a, arn, tags)
+}
+
+func (a *ACM) UpdateTags(action, arn string, tags []Tag) (string, error) {
+	return updateTags(a, action, arn, tags)
+}
+
+func (a *ACM) BulkUpdateTags(action string, arns []string, tags []Tag, dryRun bool) ([]string, error) {
+	return bulkUpdateTags(a, action, arns, tags, dryRun)
+}
+
+func (a *ACM) ListTagDescriptions(arns []string) ([]TagDescription, error) {
+	return listTagDescriptions(a, arns)
+}
+
 func createACMDeleteCertificateInput(arn string) *acm.DeleteCertificateInput {
 	dinput := &acm.DeleteCertificateInput{}
 
@@ -181,11 +428,20 @@ func (a *ACM) Delete(arn string) (string, error) {
 	return fmt.Sprintf("Deleted %s", arn), err
 }
 
-func (a *ACM) ReadableList(descs []ACMDescription) {
+func (a *ACM) ReadableList(descs []ACMDescription, expandNames bool) {
 	table := tablewriter.NewWriter(os.Stdout)
 
-	table.SetHeader([]string{"Name tag", "Domain Name", "Additional Name", "In Use?", "Not After", "Certificate Arn"})
-	table.SetAutoMergeCells(true)
+	withRegion := false
+	for _, desc := range descs {
+		withRegion = withRegion || desc.region != ""
+	}
+
+	header := []string{"Name tag", "Domain Name", "Additional Names", "Status", "In Use?", "Not After", "Certificate Arn"}
+	if withRegion {
+		header = append([]string{"Region"}, header...)
+	}
+	table.SetHeader(header)
+	table.SetAutoMergeCells(expandNames)
 	table.SetRowLine(true)
 
 	for _, desc := range descs {
@@ -193,13 +449,22 @@ func (a *ACM) ReadableList(descs []ACMDescription) {
 		if len(desc.inUseBy) > 0 {
 			inUse = "Yes"
 		}
-		for _, name := range desc.subjectAlternativeNames {
-			if name == desc.domainName {
-				continue
+
+		additionalNames := desc.names()[1:]
+		if !expandNames || len(additionalNames) == 0 {
+			additionalNames = []string{strings.Join(additionalNames, " ")}
+		}
+
+		for _, name := range additionalNames {
+			row := []string{desc.nameTag, desc.domainName, name, desc.status, inUse, desc.notAfter.String(), desc.arn}
+			if withRegion {
+				row = append([]string{desc.region}, row...)
 			}
-			table.Append([]string{desc.nameTag, desc.domainName, name, inUse, desc.notAfter.String(), desc.arn})
+			table.Append(row)
 		}
 	}
 
 	table.Render()
+
+	fmt.Printf("%d certificate(s)\n", len(descs))
 }
@@ -0,0 +1,378 @@
+package certutils
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"golang.org/x/crypto/acme"
+)
+
+const (
+	LetsEncryptDirectoryURL = "https://acme-v02.api.letsencrypt.org/directory"
+
+	challengeDNS01  = "dns-01"
+	challengeHTTP01 = "http-01"
+
+	acmeChallengePrefix = "_acme-challenge."
+)
+
+type ACMEOptions struct {
+	DirectoryURL   string
+	CABundle       []byte
+	AccountKeyPath string
+	Email          string
+	AgreeTOS       bool
+	Challenge      string
+	HostedZoneID   string
+	Webroot        string
+}
+
+type ACMEIssuer struct {
+	client       *acme.Client
+	route53      *route53.Route53
+	email        string
+	agreeTOS     bool
+	challenge    string
+	hostedZoneID string
+	webroot      string
+}
+
+type acmeChallenges struct {
+	challenges []*acme.Challenge
+	records    map[string][]string
+	files      []string
+}
+
+func loadACMEAccountKey(fpath string) (crypto.Signer, error) {
+	data, err := ioutil.ReadFile(fpath)
+	if err == nil {
+		return ParsePrivateKey(data, &PassphraseSource{})
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key, err := GeneratePrivateKey("ecdsa", 256)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = encodePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, ioutil.WriteFile(fpath, data, 0600)
+}
+
+func acmeHTTPClient(caBundle []byte) (*http.Client, error) {
+	if len(caBundle) == 0 {
+		return http.DefaultClient, nil
+	}
+
+	pool, err := certificatePool(caBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}, nil
+}
+
+func NewACMEIssuer(sess *session.Session, opts ACMEOptions) (*ACMEIssuer, error) {
+	if !opts.AgreeTOS {
+		return nil, fmt.Errorf("You must agree to the terms of service of the ACME CA(--agree-tos)")
+	}
+
+	switch opts.Challenge {
+	case challengeDNS01:
+	case challengeHTTP01:
+		if opts.Webroot == "" {
+			return nil, fmt.Errorf("--webroot is required for the http-01 challenge")
+		}
+	default:
+		return nil, fmt.Errorf("Invalid challenge %s. Supported challenges are dns-01 and http-01", opts.Challenge)
+	}
+
+	key, err := loadACMEAccountKey(opts.AccountKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := acmeHTTPClient(opts.CABundle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ACMEIssuer{
+		client: &acme.Client{
+			Key:          key,
+			DirectoryURL: opts.DirectoryURL,
+			HTTPClient:   httpClient,
+		},
+		route53:      route53.New(sess),
+		email:        opts.Email,
+		agreeTOS:     opts.AgreeTOS,
+		challenge:    opts.Challenge,
+		hostedZoneID: opts.HostedZoneID,
+		webroot:      opts.Webroot,
+	}, nil
+}
+
+func (ai *ACMEIssuer) register(ctx context.Context) error {
+	acct := &acme.Account{}
+	if ai.email != "" {
+		acct.Contact = []string{"mailto:" + ai.email}
+	}
+
+	_, err := ai.client.Register(ctx, acct, acme.AcceptTOS)
+	if err == acme.ErrAccountAlreadyExists {
+		return nil
+	}
+
+	return err
+}
+
+func (ai *ACMEIssuer) hostedZone(name string) (string, error) {
+	if ai.hostedZoneID != "" {
+		return ai.hostedZoneID, nil
+	}
+
+	fqdn := strings.TrimSuffix(name, ".") + "."
+
+	var zoneID, zoneName string
+	err := ai.route53.ListHostedZonesPages(&route53.ListHostedZonesInput{},
+		func(out *route53.ListHostedZonesOutput, lastPage bool) bool {
+			for _, zone := range out.HostedZones {
+				if zone.Config != nil && aws.BoolValue(zone.Config.PrivateZone) {
+					continue
+				}
+
+				n := aws.StringValue(zone.Name)
+				if (fqdn == n || strings.HasSuffix(fqdn, "."+n)) && len(n) > len(zoneName) {
+					zoneID, zoneName = aws.StringValue(zone.Id), n
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return "", err
+	}
+
+	if zoneID == "" {
+		return "", fmt.Errorf("Hosted zone for %s not found", name)
+	}
+
+	return zoneID, nil
+}
+
+func (ai *ACMEIssuer) changeTXTRecords(ctx context.Context, action string, records map[string][]string) error {
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		zoneID, err := ai.hostedZone(name)
+		if err != nil {
+			return err
+		}
+
+		values := make([]*route53.ResourceRecord, 0, len(records[name]))
+		for _, v := range records[name] {
+			values = append(values, &route53.ResourceRecord{Value: aws.String(fmt.Sprintf("%q", v))})
+		}
+
+		out, err := ai.route53.ChangeResourceRecordSetsWithContext(ctx, &route53.ChangeResourceRecordSetsInput{
+			HostedZoneId: aws.String(zoneID),
+			ChangeBatch: &route53.ChangeBatch{
+				Changes: []*route53.Change{
+					&route53.Change{
+						Action: aws.String(action),
+						ResourceRecordSet: &route53.ResourceRecordSet{
+							Name:            aws.String(name),
+							Type:            aws.String(route53.RRTypeTxt),
+							TTL:             aws.Int64(60),
+							ResourceRecords: values,
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if action != route53.ChangeActionUpsert {
+			continue
+		}
+
+		err = ai.route53.WaitUntilResourceRecordSetsChangedWithContext(ctx, &route53.GetChangeInput{Id: out.ChangeInfo.Id})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ai *ACMEIssuer) prepare(ctx context.Context, authzURLs []string) (*acmeChallenges, error) {
+	prepared := &acmeChallenges{
+		challenges: make([]*acme.Challenge, 0, len(authzURLs)),
+		records:    make(map[string][]string),
+		files:      make([]string, 0),
+	}
+
+	for _, u := range authzURLs {
+		authz, err := ai.client.GetAuthorization(ctx, u)
+		if err != nil {
+			return prepared, err
+		}
+
+		if authz.Status == acme.StatusValid {
+			continue
+		}
+
+		var chal *acme.Challenge
+		for _, c := range authz.Challenges {
+			if c.Type == ai.challenge {
+				chal = c
+				break
+			}
+		}
+
+		if chal == nil {
+			return prepared, fmt.Errorf("The ACME CA does not offer the %s challenge for %s", ai.challenge, authz.Identifier.Value)
+		}
+
+		switch ai.challenge {
+		case challengeDNS01:
+			record, err := ai.client.DNS01ChallengeRecord(chal.Token)
+			if err != nil {
+				return prepared, err
+			}
+
+			name := acmeChallengePrefix + authz.Identifier.Value + "."
+			prepared.records[name] = append(prepared.records[name], record)
+		case challengeHTTP01:
+			body, err := ai.client.HTTP01ChallengeResponse(chal.Token)
+			if err != nil {
+				return prepared, err
+			}
+
+			fpath := filepath.Join(ai.webroot, filepath.FromSlash(ai.client.HTTP01ChallengePath(chal.Token)))
+			err = os.MkdirAll(filepath.Dir(fpath), 0755)
+			if err != nil {
+				return prepared, err
+			}
+
+			err = ioutil.WriteFile(fpath, []byte(body), 0644)
+			if err != nil {
+				return prepared, err
+			}
+			prepared.files = append(prepared.files, fpath)
+		}
+
+		prepared.challenges = append(prepared.challenges, chal)
+	}
+
+	if len(prepared.records) > 0 {
+		return prepared, ai.changeTXTRecords(ctx, route53.ChangeActionUpsert, prepared.records)
+	}
+
+	return prepared, nil
+}
+
+func (ai *ACMEIssuer) cleanup(prepared *acmeChallenges) {
+	for _, fpath := range prepared.files {
+		os.Remove(fpath)
+	}
+
+	if len(prepared.records) > 0 {
+		ai.changeTXTRecords(context.Background(), route53.ChangeActionDelete, prepared.records)
+	}
+}
+
+func (ai *ACMEIssuer) Issue(ctx context.Context, domains []string, key crypto.Signer) ([]byte, []byte, error) {
+	if len(domains) == 0 {
+		return []byte{}, []byte{}, fmt.Errorf("At least one domain is required")
+	}
+
+	err := ai.register(ctx)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	order, err := ai.client.AuthorizeOrder(ctx, acme.DomainIDs(domains...))
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	prepared, err := ai.prepare(ctx, order.AuthzURLs)
+	defer ai.cleanup(prepared)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	for _, chal := range prepared.challenges {
+		_, err = ai.client.Accept(ctx, chal)
+		if err != nil {
+			return []byte{}, []byte{}, err
+		}
+	}
+
+	for _, u := range order.AuthzURLs {
+		_, err = ai.client.WaitAuthorization(ctx, u)
+		if err != nil {
+			return []byte{}, []byte{}, err
+		}
+	}
+
+	order, err = ai.client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		DNSNames: domains,
+	}, key)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	ders, _, err := ai.client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	if len(ders) == 0 {
+		return []byte{}, []byte{}, fmt.Errorf("The ACME CA returned no certificate")
+	}
+
+	chain := make([]byte, 0)
+	for _, der := range ders[1:] {
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ders[0]}), chain, nil
+}
@@ -17,6 +17,7 @@ type ALB struct {
 type ALBDescription struct {
 	name    string
 	dnsname string
+	region  string
 	certs   []ALBCertificate
 }
 
@@ -152,18 +153,25 @@ func (alb *ALB) BulkUpdate(srcCertArn, destCertArn string, dryRun bool) ([]strin
 		updates = append(updates, dryRunMsg()...)
 	}
 
+	msgs, err := alb.updateListeners(lbs, srcCertArn, destCertArn, dryRun)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return append(updates, msgs...), nil
+}
+
+func (alb *ALB) updateListeners(lbs []ALBDescription, srcCertArn, destCertArn string, dryRun bool) ([]string, error) {
+	updates := make([]string, 0)
 	for _, lb := range lbs {
 		for _, cert := range lb.certs {
-			if dryRun {
-				updates = append(updates, albUpdateMsg(lb.name, cert.port, srcCertArn, destCertArn))
-			} else {
+			if !dryRun {
 				_, err := alb.client.ModifyListener(createALBModifyListenerInput(cert.listenerArn, destCertArn))
-
 				if err != nil {
-					return []string{}, err
+					return updates, err
 				}
-				updates = append(updates, albUpdateMsg(lb.name, cert.port, srcCertArn, destCertArn))
 			}
+			updates = append(updates, regionMsg(lb.region, albUpdateMsg(lb.name, cert.port, srcCertArn, destCertArn)))
 		}
 	}
 
@@ -173,13 +181,26 @@ func (alb *ALB) BulkUpdate(srcCertArn, destCertArn string, dryRun bool) ([]strin
 func (alb *ALB) ReadableList(descs []ALBDescription) {
 	table := tablewriter.NewWriter(os.Stdout)
 
-	table.SetHeader([]string{"Name", "Port", "Listener SSL Certificate"})
+	withRegion := false
+	for _, desc := range descs {
+		withRegion = withRegion || desc.region != ""
+	}
+
+	header := []string{"Name", "Port", "Listener SSL Certificate"}
+	if withRegion {
+		header = append([]string{"Region"}, header...)
+	}
+	table.SetHeader(header)
 	table.SetAutoMergeCells(true)
 	table.SetRowLine(true)
 
 	for _, desc := range descs {
 		for _, cert := range desc.certs {
-			table.Append([]string{desc.name, fmt.Sprint(cert.port), cert.arn})
+			row := []string{desc.name, fmt.Sprint(cert.port), cert.arn}
+			if withRegion {
+				row = append([]string{desc.region}, row...)
+			}
+			table.Append(row)
 		}
 	}
 
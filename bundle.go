@@ -0,0 +1,179 @@
+package certutils
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func readBundleFiles(fpath string) ([][]byte, error) {
+	info, err := os.Stat(fpath)
+	if err != nil {
+		return [][]byte{}, err
+	}
+
+	if !info.IsDir() {
+		data, err := readFile(fpath)
+		return [][]byte{data}, err
+	}
+
+	files := make([][]byte, 0)
+	err = filepath.Walk(fpath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		data, err := readFile(path)
+		if err != nil {
+			return err
+		}
+
+		if len(pemBlocks(data, "")) == 0 {
+			return nil
+		}
+		files = append(files, data)
+
+		return nil
+	})
+
+	return files, err
+}
+
+func uniqueCertificates(certs []*x509.Certificate) []*x509.Certificate {
+	uniq := make([]*x509.Certificate, 0, len(certs))
+	for _, cert := range certs {
+		found := false
+		for _, u := range uniq {
+			if bytes.Equal(cert.Raw, u.Raw) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			uniq = append(uniq, cert)
+		}
+	}
+
+	return uniq
+}
+
+func encodeCertificates(certs []*x509.Certificate) []byte {
+	blocks := make([]*pem.Block, 0, len(certs))
+	for _, cert := range certs {
+		blocks = append(blocks, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+
+	return encodePEMBlocks(blocks)
+}
+
+func findLeafCertificate(certs []*x509.Certificate, pkey []byte) (*x509.Certificate, error) {
+	if len(pkey) > 0 {
+		for _, cert := range certs {
+			_, err := tls.X509KeyPair(encodeCertificates([]*x509.Certificate{cert}), pkey)
+			if err == nil {
+				return cert, nil
+			}
+		}
+
+		return nil, fmt.Errorf("Certificate matching the private key not found")
+	}
+
+	leaves := make([]*x509.Certificate, 0)
+	for _, cert := range certs {
+		if cert.IsCA || isSelfSigned(cert) {
+			continue
+		}
+		leaves = append(leaves, cert)
+	}
+
+	if len(leaves) != 1 {
+		return nil, fmt.Errorf("Found %d leaf certificates. Specify the private key to identify the leaf certificate", len(leaves))
+	}
+
+	return leaves[0], nil
+}
+
+func BuildChain(leaf *x509.Certificate, certs []*x509.Certificate) []*x509.Certificate {
+	chain := make([]*x509.Certificate, 0)
+
+	parent := leaf
+	for {
+		var issuer *x509.Certificate
+		for _, cert := range certs {
+			if cert == parent || bytes.Equal(cert.Raw, leaf.Raw) {
+				continue
+			}
+
+			if isIssuedBy(parent, cert) {
+				issuer = cert
+				break
+			}
+		}
+
+		if issuer == nil || isSelfSigned(issuer) || len(chain) >= len(certs) {
+			break
+		}
+
+		chain = append(chain, issuer)
+		parent = issuer
+	}
+
+	return chain
+}
+
+func (cm *CertificateManager) LoadBundle(fpath string, passphrase *PassphraseSource) error {
+	files, err := readBundleFiles(fpath)
+	if err != nil {
+		return err
+	}
+
+	certs := make([]*x509.Certificate, 0)
+	for _, data := range files {
+		parsed, err := ParseCertificates(data)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, parsed...)
+
+		if len(cm.Pkey) == 0 {
+			for _, block := range pemBlocks(data, "") {
+				if strings.HasSuffix(block.Type, "PRIVATE KEY") {
+					cm.Pkey, err = NormalizePrivateKey(pem.EncodeToMemory(block), passphrase)
+					if err != nil {
+						return err
+					}
+					break
+				}
+			}
+		}
+	}
+
+	return cm.setCertificates(certs, fpath)
+}
+
+func (cm *CertificateManager) setCertificates(certs []*x509.Certificate, source string) error {
+	certs = uniqueCertificates(certs)
+	if len(certs) == 0 {
+		return fmt.Errorf("Certificate not found in %s", source)
+	}
+
+	leaf, err := findLeafCertificate(certs, cm.Pkey)
+	if err != nil {
+		return err
+	}
+
+	cm.Cert = encodeCertificates([]*x509.Certificate{leaf})
+	cm.Chain = encodeCertificates(BuildChain(leaf, certs))
+
+	return nil
+}
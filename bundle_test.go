@@ -0,0 +1,173 @@
+package certutils
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func subjects(certs []*x509.Certificate) string {
+	names := make([]string, 0, len(certs))
+	for _, cert := range certs {
+		names = append(names, cert.Subject.CommonName)
+	}
+
+	return strings.Join(names, ",")
+}
+
+func TestFindLeafCertificate(t *testing.T) {
+	root := newTestCertificate(t, "Test Root", nil, true)
+	inter := newTestCertificate(t, "Test Intermediate", root, true)
+	leaf1 := newTestCertificate(t, "www.example.com", inter, false)
+	leaf2 := newTestCertificate(t, "api.example.com", inter, false)
+	other := newTestCertificate(t, "other.example.com", root, false)
+
+	tests := []struct {
+		name  string
+		certs []*testCertificate
+		pkey  []byte
+		leaf  string
+		err   string
+	}{
+		{
+			name:  "single leaf",
+			certs: []*testCertificate{root, leaf1, inter},
+			leaf:  "www.example.com",
+		},
+		{
+			name:  "two leaves without a key",
+			certs: []*testCertificate{leaf1, inter, leaf2},
+			err:   "Found 2 leaf certificates",
+		},
+		{
+			name:  "two leaves with a key",
+			certs: []*testCertificate{leaf1, inter, leaf2},
+			pkey:  leaf2.keyPEM(t),
+			leaf:  "api.example.com",
+		},
+		{
+			name:  "key without a certificate",
+			certs: []*testCertificate{leaf1, inter, leaf2},
+			pkey:  other.keyPEM(t),
+			err:   "Certificate matching the private key not found",
+		},
+		{
+			name:  "only CA certificates",
+			certs: []*testCertificate{root, inter},
+			err:   "Found 0 leaf certificates",
+		},
+	}
+
+	for _, tt := range tests {
+		certs := make([]*x509.Certificate, 0, len(tt.certs))
+		for _, c := range tt.certs {
+			certs = append(certs, c.cert)
+		}
+
+		leaf, err := findLeafCertificate(certs, tt.pkey)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", tt.name, err)
+			continue
+		}
+
+		if leaf.Subject.CommonName != tt.leaf {
+			t.Errorf("%s: got %s, want %s", tt.name, leaf.Subject.CommonName, tt.leaf)
+		}
+	}
+}
+
+func TestBuildChain(t *testing.T) {
+	root := newTestCertificate(t, "Test Root", nil, true)
+	inter1 := newTestCertificate(t, "Test Intermediate 1", root, true)
+	inter2 := newTestCertificate(t, "Test Intermediate 2", inter1, true)
+	leaf := newTestCertificate(t, "www.example.com", inter2, false)
+	unrelated := newTestCertificate(t, "Unrelated Intermediate", root, true)
+
+	tests := []struct {
+		name  string
+		certs []*testCertificate
+		chain string
+	}{
+		{
+			name:  "ordered",
+			certs: []*testCertificate{leaf, inter2, inter1, root},
+			chain: "Test Intermediate 2,Test Intermediate 1",
+		},
+		{
+			name:  "shuffled",
+			certs: []*testCertificate{root, inter1, leaf, unrelated, inter2},
+			chain: "Test Intermediate 2,Test Intermediate 1",
+		},
+		{
+			name:  "missing intermediate",
+			certs: []*testCertificate{leaf, inter1, root},
+			chain: "",
+		},
+		{
+			name:  "leaf only",
+			certs: []*testCertificate{leaf},
+			chain: "",
+		},
+	}
+
+	for _, tt := range tests {
+		certs := make([]*x509.Certificate, 0, len(tt.certs))
+		for _, c := range tt.certs {
+			certs = append(certs, c.cert)
+		}
+
+		got := subjects(BuildChain(leaf.cert, certs))
+		if got != tt.chain {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.chain)
+		}
+	}
+}
+
+func TestLoadBundleDirectory(t *testing.T) {
+	root := newTestCertificate(t, "Test Root", nil, true)
+	inter := newTestCertificate(t, "Test Intermediate", root, true)
+	leaf := newTestCertificate(t, "www.example.com", inter, false)
+
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{
+		"a-root.crt":   root.pem(),
+		"b-leaf.crt":   leaf.pem(),
+		"c-inter.crt":  inter.pem(),
+		"d-readme.txt": []byte("not a certificate"),
+	}
+	for name, data := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), data, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cm := NewCertificateManager()
+	err = cm.Load(CertificateSource{BundlePath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(cm.Cert) != string(leaf.pem()) {
+		t.Errorf("got certificate %q, want the leaf", cm.Cert)
+	}
+
+	if string(cm.Chain) != string(inter.pem()) {
+		t.Errorf("got chain %q, want the intermediate only", cm.Chain)
+	}
+}
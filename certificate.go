@@ -0,0 +1,239 @@
+package certutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+type CertificateInfo struct {
+	Subject            string    `json:"subject"`
+	Issuer             string    `json:"issuer"`
+	SerialNumber       string    `json:"serial_number"`
+	DNSNames           []string  `json:"dns_names"`
+	IPAddresses        []string  `json:"ip_addresses"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	KeyType            string    `json:"key_type"`
+	KeySize            int       `json:"key_size"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	SHA1Fingerprint    string    `json:"sha1_fingerprint"`
+	SHA256Fingerprint  string    `json:"sha256_fingerprint"`
+	KeyUsage           []string  `json:"key_usage"`
+	ExtKeyUsage        []string  `json:"ext_key_usage"`
+	KeyMatch           *bool     `json:"key_match,omitempty"`
+}
+
+var keyUsageNames = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "Digital Signature"},
+	{x509.KeyUsageContentCommitment, "Content Commitment"},
+	{x509.KeyUsageKeyEncipherment, "Key Encipherment"},
+	{x509.KeyUsageDataEncipherment, "Data Encipherment"},
+	{x509.KeyUsageKeyAgreement, "Key Agreement"},
+	{x509.KeyUsageCertSign, "Certificate Sign"},
+	{x509.KeyUsageCRLSign, "CRL Sign"},
+	{x509.KeyUsageEncipherOnly, "Encipher Only"},
+	{x509.KeyUsageDecipherOnly, "Decipher Only"},
+}
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:             "Any",
+	x509.ExtKeyUsageServerAuth:      "TLS Web Server Authentication",
+	x509.ExtKeyUsageClientAuth:      "TLS Web Client Authentication",
+	x509.ExtKeyUsageCodeSigning:     "Code Signing",
+	x509.ExtKeyUsageEmailProtection: "E-mail Protection",
+	x509.ExtKeyUsageTimeStamping:    "Time Stamping",
+	x509.ExtKeyUsageOCSPSigning:     "OCSP Signing",
+}
+
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	blocks := pemBlocks(data, "CERTIFICATE")
+	if len(blocks) == 0 {
+		if _, rest := pem.Decode(data); len(rest) != len(data) {
+			return []*x509.Certificate{}, nil
+		}
+
+		certs, err := x509.ParseCertificates(data)
+		if err != nil {
+			return []*x509.Certificate{}, err
+		}
+
+		return certs, nil
+	}
+
+	certs := make([]*x509.Certificate, 0, len(blocks))
+	for _, block := range blocks {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return []*x509.Certificate{}, err
+		}
+		certs = append(certs, cert)
+	}
+
+	return certs, nil
+}
+
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("Certificate not found")
+	}
+
+	return certs[0], nil
+}
+
+func fingerprint(sum []byte) string {
+	hexes := make([]string, 0, len(sum))
+	for _, b := range sum {
+		hexes = append(hexes, fmt.Sprintf("%02X", b))
+	}
+
+	return strings.Join(hexes, ":")
+}
+
+func SHA1Fingerprint(cert *x509.Certificate) string {
+	sum := sha1.Sum(cert.Raw)
+	return fingerprint(sum[:])
+}
+
+func SHA256Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return fingerprint(sum[:])
+}
+
+func publicKeyInfo(pub interface{}) (string, int) {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA", key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", 256
+	}
+
+	return "Unknown", 0
+}
+
+func keyUsages(usage x509.KeyUsage) []string {
+	usages := make([]string, 0)
+	for _, ku := range keyUsageNames {
+		if usage&ku.usage != 0 {
+			usages = append(usages, ku.name)
+		}
+	}
+
+	return usages
+}
+
+func extKeyUsages(usages []x509.ExtKeyUsage) []string {
+	names := make([]string, 0, len(usages))
+	for _, usage := range usages {
+		name, ok := extKeyUsageNames[usage]
+		if !ok {
+			name = fmt.Sprintf("Unknown (%d)", usage)
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+func InspectCertificate(certBlock, keyBlock []byte) (CertificateInfo, error) {
+	cert, err := ParseCertificate(certBlock)
+	if err != nil {
+		return CertificateInfo{}, err
+	}
+
+	keyType, keySize := publicKeyInfo(cert.PublicKey)
+
+	ips := make([]string, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+
+	info := CertificateInfo{
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		SerialNumber:       fingerprint(cert.SerialNumber.Bytes()),
+		DNSNames:           cert.DNSNames,
+		IPAddresses:        ips,
+		NotBefore:          cert.NotBefore,
+		NotAfter:           cert.NotAfter,
+		KeyType:            keyType,
+		KeySize:            keySize,
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		SHA1Fingerprint:    SHA1Fingerprint(cert),
+		SHA256Fingerprint:  SHA256Fingerprint(cert),
+		KeyUsage:           keyUsages(cert.KeyUsage),
+		ExtKeyUsage:        extKeyUsages(cert.ExtKeyUsage),
+	}
+
+	if len(keyBlock) > 0 {
+		_, err := tls.X509KeyPair(certBlock, keyBlock)
+		match := err == nil
+		info.KeyMatch = &match
+	}
+
+	return info, nil
+}
+
+func ReadableCertificateInfo(info CertificateInfo) {
+	table := tablewriter.NewWriter(os.Stdout)
+
+	table.SetHeader([]string{"Field", "Value"})
+	table.SetAutoWrapText(false)
+
+	table.Append([]string{"Subject", info.Subject})
+	table.Append([]string{"Issuer", info.Issuer})
+	table.Append([]string{"Serial Number", info.SerialNumber})
+	table.Append([]string{"DNS Names", strings.Join(info.DNSNames, " ")})
+	table.Append([]string{"IP Addresses", strings.Join(info.IPAddresses, " ")})
+	table.Append([]string{"Not Before", info.NotBefore.String()})
+	table.Append([]string{"Not After", info.NotAfter.String()})
+	table.Append([]string{"Key", fmt.Sprintf("%s %d bit", info.KeyType, info.KeySize)})
+	table.Append([]string{"Signature Algorithm", info.SignatureAlgorithm})
+	table.Append([]string{"SHA-1 Fingerprint", info.SHA1Fingerprint})
+	table.Append([]string{"SHA-256 Fingerprint", info.SHA256Fingerprint})
+	table.Append([]string{"Key Usage", strings.Join(info.KeyUsage, ", ")})
+	table.Append([]string{"Extended Key Usage", strings.Join(info.ExtKeyUsage, ", ")})
+
+	if info.KeyMatch != nil {
+		match := "No"
+		if *info.KeyMatch {
+			match = "Yes"
+		}
+		table.Append([]string{"Private Key Matches", match})
+	}
+
+	table.Render()
+}
+
+func JSONCertificateInfo(info CertificateInfo) error {
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(data))
+
+	return nil
+}
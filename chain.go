@@ -0,0 +1,107 @@
+package certutils
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+)
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(cert) == nil
+}
+
+func isIssuedBy(cert, issuer *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, issuer.RawSubject) && cert.CheckSignatureFrom(issuer) == nil
+}
+
+func certificatePool(data []byte) (*x509.CertPool, error) {
+	if len(data) == 0 {
+		return x509.SystemCertPool()
+	}
+
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("CA bundle has no certificates")
+	}
+
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+
+	return pool, nil
+}
+
+func checkChainOrder(leaf *x509.Certificate, chain []*x509.Certificate) error {
+	parent := leaf
+	for i, cert := range chain {
+		if bytes.Equal(cert.Raw, leaf.Raw) {
+			return fmt.Errorf("Certificate chain includes the certificate itself at position %d", i+1)
+		}
+
+		if isSelfSigned(cert) {
+			return fmt.Errorf("Certificate chain includes the root certificate %s at position %d. Remove it from the chain", cert.Subject, i+1)
+		}
+
+		if isIssuedBy(parent, cert) {
+			parent = cert
+			continue
+		}
+
+		for j := i + 1; j < len(chain); j++ {
+			if isIssuedBy(parent, chain[j]) {
+				return fmt.Errorf("Certificate chain is in the wrong order. %s (position %d) must come before %s (position %d)", chain[j].Subject, j+1, cert.Subject, i+1)
+			}
+		}
+
+		return fmt.Errorf("Certificate chain is broken. %s is not issued by %s (position %d). The intermediate certificate for %s is missing", parent.Subject, cert.Subject, i+1, parent.Issuer)
+	}
+
+	return nil
+}
+
+func VerifyChain(certBlock, chainBlock, caBundle []byte) error {
+	leaf, err := ParseCertificate(certBlock)
+	if err != nil {
+		return err
+	}
+
+	chain, err := ParseCertificates(chainBlock)
+	if err != nil {
+		return err
+	}
+
+	err = checkChainOrder(leaf, chain)
+	if err != nil {
+		return err
+	}
+
+	roots, err := certificatePool(caBundle)
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		last := leaf
+		if len(chain) > 0 {
+			last = chain[len(chain)-1]
+		}
+		return fmt.Errorf("Failed to verify the certificate chain: %s. The intermediate certificate for %s is missing, or the root is not in the trust store", err, last.Issuer)
+	}
+
+	return nil
+}
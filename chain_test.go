@@ -0,0 +1,149 @@
+package certutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCertificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCertificate(t *testing.T, cn string, parent *testCertificate, isCA bool) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		tmpl.DNSNames = []string{cn}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	issuer, signer := tmpl, key
+	if parent != nil {
+		issuer, signer = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCertificate{cert: cert, key: key}
+}
+
+func (c *testCertificate) pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+func (c *testCertificate) keyPEM(t *testing.T) []byte {
+	data, err := encodePrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func joinPEM(certs ...*testCertificate) []byte {
+	data := make([]byte, 0)
+	for _, c := range certs {
+		data = append(data, c.pem()...)
+	}
+
+	return data
+}
+
+func TestVerifyChain(t *testing.T) {
+	root := newTestCertificate(t, "Test Root", nil, true)
+	inter1 := newTestCertificate(t, "Test Intermediate 1", root, true)
+	inter2 := newTestCertificate(t, "Test Intermediate 2", inter1, true)
+	leaf := newTestCertificate(t, "www.example.com", inter2, false)
+	otherRoot := newTestCertificate(t, "Other Root", nil, true)
+
+	tests := []struct {
+		name     string
+		chain    []*testCertificate
+		caBundle *testCertificate
+		err      string
+	}{
+		{
+			name:     "ordered",
+			chain:    []*testCertificate{inter2, inter1},
+			caBundle: root,
+		},
+		{
+			name:     "leaf included",
+			chain:    []*testCertificate{leaf, inter2, inter1},
+			caBundle: root,
+			err:      "includes the certificate itself at position 1",
+		},
+		{
+			name:     "root included",
+			chain:    []*testCertificate{inter2, inter1, root},
+			caBundle: root,
+			err:      "includes the root certificate CN=Test Root at position 3",
+		},
+		{
+			name:     "reversed",
+			chain:    []*testCertificate{inter1, inter2},
+			caBundle: root,
+			err:      "wrong order. CN=Test Intermediate 2 (position 2) must come before CN=Test Intermediate 1 (position 1)",
+		},
+		{
+			name:     "missing intermediate",
+			chain:    []*testCertificate{inter1},
+			caBundle: root,
+			err:      "The intermediate certificate for CN=Test Intermediate 2 is missing",
+		},
+		{
+			name:     "untrusted root",
+			chain:    []*testCertificate{inter2, inter1},
+			caBundle: otherRoot,
+			err:      "Failed to verify the certificate chain",
+		},
+	}
+
+	for _, tt := range tests {
+		err := VerifyChain(leaf.pem(), joinPEM(tt.chain...), tt.caBundle.pem())
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %q", tt.name, err)
+		case tt.err != "" && err == nil:
+			t.Errorf("%s: expected an error containing %q", tt.name, tt.err)
+		case tt.err != "" && !strings.Contains(err.Error(), tt.err):
+			t.Errorf("%s: got %q, want it to contain %q", tt.name, err, tt.err)
+		}
+	}
+}
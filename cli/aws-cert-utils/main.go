@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"crypto"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/tkuchiki/aws-cert-utils"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
@@ -20,23 +25,104 @@ var (
 	awsProfile         = crtUtils.Flag("profile", "The AWS CLI profile").String()
 	awsConfig          = crtUtils.Flag("aws-config", "The AWS CLI Config file").String()
 	awsCreds           = crtUtils.Flag("credentials", "The AWS CLI Credential file").String()
+	refuseInlinePkey   = crtUtils.Flag("refuse-inline-pkey", "Refuse private keys passed as plain --pkey flag values").Envar("AWS_CERT_UTILS_REFUSE_INLINE_PKEY").Bool()
+	notifyConfig       = crtUtils.Flag("notify-config", "Path to a YAML file configuring the notification sinks(webhook, smtp, sns)").Envar("AWS_CERT_UTILS_NOTIFY_CONFIG").String()
+
+	// cert
+	certCmd = crtUtils.Command("cert", "Local certificate utilities")
+	// cert inspect
+	certInspectCmd      = certCmd.Command("inspect", "Shows the details of a local certificate")
+	certInspectCertPath = certInspectCmd.Flag("cert-path", "Path to certificate").String()
+	certInspectPkeyPath = certInspectCmd.Flag("pkey-path", "Path to private key(- for stdin, env:NAME, fd:N or prompt: for a hidden prompt)").String()
+	certInspectCert     = certInspectCmd.Flag("cert", "The certificate to inspect").String()
+	certInspectPkey     = certInspectCmd.Flag("pkey", "The private key to check against the certificate").String()
+	certInspectFormat   = certInspectCmd.Flag("format", "The output format").Default("table").Enum("table", "json")
+
+	// cert keygen
+	certKeygenCmd     = certCmd.Command("keygen", "Generates a private key")
+	certKeygenKeyType = certKeygenCmd.Flag("key-type", "The key type").Default("rsa").Enum("rsa", "ecdsa")
+	certKeygenBits    = certKeygenCmd.Flag("bits", "The key size(rsa: 2048, 3072, 4096, ecdsa: 256, 384. default: 2048 for rsa, 256 for ecdsa)").Int()
+	certKeygenService = certKeygenCmd.Flag("service", "The service the key must be compatible with").Default("acm").Enum("acm", "iam", "cloudfront")
+	certKeygenOut     = certKeygenCmd.Flag("out", "Path to write the private key to(default: stdout)").String()
+
+	// cert csr
+	certCSRCmd        = certCmd.Command("csr", "Generates a certificate signing request")
+	certCSRConfig     = certCSRCmd.Flag("config", "Path to a YAML file with common_name, organization, organizational_unit, country, province, locality and dns_names").String()
+	certCSRCommonName = certCSRCmd.Flag("common-name", "The common name").String()
+	certCSROrg        = certCSRCmd.Flag("organization", "The organization").String()
+	certCSROrgUnit    = certCSRCmd.Flag("organizational-unit", "The organizational unit").String()
+	certCSRCountry    = certCSRCmd.Flag("country", "The country").String()
+	certCSRProvince   = certCSRCmd.Flag("province", "The state or province").String()
+	certCSRLocality   = certCSRCmd.Flag("locality", "The locality").String()
+	certCSRDNSNames   = certCSRCmd.Flag("dns-name", "The subject alternative name(repeatable, default: the common name)").Strings()
+	certCSRPkeyPath   = certCSRCmd.Flag("pkey-path", "Path to an existing private key(- for stdin, env:NAME, fd:N or prompt: for a hidden prompt). If omitted, a new key is generated").String()
+	certCSRKeyType    = certCSRCmd.Flag("key-type", "The key type of the generated key").Default("rsa").Enum("rsa", "ecdsa")
+	certCSRBits       = certCSRCmd.Flag("bits", "The key size of the generated key(rsa: 2048, 3072, 4096, ecdsa: 256, 384. default: 2048 for rsa, 256 for ecdsa)").Int()
+	certCSRService    = certCSRCmd.Flag("service", "The service the key must be compatible with").Default("acm").Enum("acm", "iam", "cloudfront")
+	certCSRKeyOut     = certCSRCmd.Flag("key-out", "Path to write the generated private key to").String()
+	certCSROut        = certCSRCmd.Flag("out", "Path to write the certificate signing request to(default: stdout)").String()
+
+	// dev-ca
+	devCACmd = crtUtils.Command("dev-ca", "Local development CA for staging environments")
+	// dev-ca init
+	devCAInitCmd        = devCACmd.Command("init", "Creates a local CA")
+	devCAInitDir        = devCAInitCmd.Flag("dir", "Directory to write ca.pem and ca-key.pem to").Default("dev-ca").String()
+	devCAInitCommonName = devCAInitCmd.Flag("common-name", "The common name of the CA").Default("aws-cert-utils development CA").String()
+	devCAInitValidFor   = devCAInitCmd.Flag("valid-for", "The validity period of the CA(e.g. 8760h, 365d, 52w)").Default("3650d").String()
+	devCAInitForce      = devCAInitCmd.Flag("force", "Overwrite an existing CA").Bool()
+
+	// dev-ca issue
+	devCAIssueCmd       = devCACmd.Command("issue", "Issues a certificate for the hostnames and optionally imports it into ACM or IAM")
+	devCAIssueDir       = devCAIssueCmd.Flag("dir", "Directory containing ca.pem and ca-key.pem").Default("dev-ca").String()
+	devCAIssueHostnames = devCAIssueCmd.Flag("hostname", "The hostname or IP address(repeatable)").Required().Strings()
+	devCAIssueValidFor  = devCAIssueCmd.Flag("valid-for", "The validity period of the certificate(e.g. 720h, 90d, 12w)").Default("90d").String()
+	devCAIssueKeyType   = devCAIssueCmd.Flag("key-type", "The key type").Default("rsa").Enum("rsa", "ecdsa")
+	devCAIssueBits      = devCAIssueCmd.Flag("bits", "The key size(rsa: 2048, 3072, 4096, ecdsa: 256, 384. default: 2048 for rsa, 256 for ecdsa)").Int()
+	devCAIssueCertOut   = devCAIssueCmd.Flag("cert-out", "Path to write the certificate to").String()
+	devCAIssueKeyOut    = devCAIssueCmd.Flag("key-out", "Path to write the private key to").String()
+	devCAIssueImport    = devCAIssueCmd.Flag("import", "Imports the certificate into ACM or IAM").Enum("acm", "iam")
+	devCAIssueName      = devCAIssueCmd.Flag("name", "The Name tag(acm) or server certificate name(iam)(default: the first hostname)").String()
+	devCAIssuePath      = devCAIssueCmd.Flag("path", "The path for the server certificate(iam)").Default("/").String()
+	devCAIssueTags      = devCAIssueCmd.Flag("tags", "Tags to add to the certificate(e.g. Key1=Value1,Key2=Value2)").String()
 
 	// acm
 	acmCmd = crtUtils.Command("acm", "AWS Certificate Manager (ACM)")
 	// acm list
-	acmListCmd      = acmCmd.Command("list", "Retrieves a list of ACM Certificates and the domain name for each")
-	acmListStatuses = acmListCmd.Flag("cert-statuses", "The status or statuses on which to filter the list of ACM Certificates(comma separated)").Default("ALL").String()
-	acmListMaxItems = acmListCmd.Flag("max-items", "The total number of items to return in the command's output").Int()
+	acmListCmd           = acmCmd.Command("list", "Retrieves a list of ACM Certificates and the domain name for each")
+	acmListStatuses      = acmListCmd.Flag("cert-statuses", "The status or statuses on which to filter the list of ACM Certificates(comma separated)").Default("ALL").String()
+	acmListMaxItems      = acmListCmd.Flag("max-items", "The total number of items to return in the command's output").Int()
+	acmListExpiresWithin = acmListCmd.Flag("expires-within", "Only list certificates that expire within the duration(e.g. 30d, 2w, 12h)").String()
+	acmListDomain        = acmListCmd.Flag("domain", "Only list certificates whose domain name or additional names match the domain(wildcard aware)").String()
+	acmListKeyTypes      = acmListCmd.Flag("key-types", "The key type or types on which to filter the list of ACM Certificates(comma separated, e.g. RSA_2048,EC_prime256v1 or ALL)(default: ALL)").String()
+	acmListTags          = acmListCmd.Flag("tag", "Only list certificates that have the tag(key=value or key, repeatable)").Strings()
+	acmListInUse         = acmListCmd.Flag("in-use", "Only list certificates that are in use").Bool()
+	acmListUnused        = acmListCmd.Flag("unused", "Only list certificates that are not in use").Bool()
+	acmListType          = acmListCmd.Flag("type", "Only list certificates of the type").Enum("imported", "amazon-issued", "private")
+	acmListSortBy        = acmListCmd.Flag("sort-by", "Sort certificates by the key").Enum("expiry", "domain", "name", "region")
+	acmListExpandNames   = acmListCmd.Flag("expand-names", "Show one row per additional name instead of collapsing them into one row").Bool()
+	acmListRegions       = acmListCmd.Flag("regions", "The regions to query concurrently(all or comma separated, e.g. us-east-1,eu-west-1)").String()
 
 	// acm import
-	acmImportCmd       = acmCmd.Command("import", "Imports an SSL/TLS certificate into AWS Certificate Manager (ACM) to use with ACM's integrated AWS services")
-	acmImportCertPath  = acmImportCmd.Flag("cert-path", "Path to certificate").String()
-	acmImportChainPath = acmImportCmd.Flag("chain-path", "Path to certificate chain").String()
-	acmImportPkeyPath  = acmImportCmd.Flag("pkey-path", "Path to private key").String()
-	acmImportCert      = acmImportCmd.Flag("cert", "The certificate to import").String()
-	acmImportChain     = acmImportCmd.Flag("chain", "The certificate chain").String()
-	acmImportPkey      = acmImportCmd.Flag("pkey", "The private key that matches the public key in the certificate").String()
-	acmImportName      = acmImportCmd.Flag("name", "The name tag value").String()
+	acmImportCmd           = acmCmd.Command("import", "Imports an SSL/TLS certificate into AWS Certificate Manager (ACM) to use with ACM's integrated AWS services")
+	acmImportCertPath      = acmImportCmd.Flag("cert-path", "Path to certificate").String()
+	acmImportChainPath     = acmImportCmd.Flag("chain-path", "Path to certificate chain").String()
+	acmImportPkeyPath      = acmImportCmd.Flag("pkey-path", "Path to private key(- for stdin, env:NAME, fd:N or prompt: for a hidden prompt)").String()
+	acmImportBundlePath    = acmImportCmd.Flag("bundle-path", "Path to a PEM bundle or a directory containing the certificate, the intermediates and the root in any order").String()
+	acmImportPKCS12Path    = acmImportCmd.Flag("pkcs12-path", "Path to a PKCS#12/PFX file containing the certificate, the certificate chain and the private key").String()
+	acmImportKeystorePath  = acmImportCmd.Flag("keystore-path", "Path to a JKS or PKCS#12 keystore").String()
+	acmImportKeystoreAlias = acmImportCmd.Flag("keystore-alias", "The alias of the private key entry in the keystore(default: prompt when the keystore has several entries)").String()
+	acmImportK8sSecretPath = acmImportCmd.Flag("k8s-secret-path", "Path to a Kubernetes kubernetes.io/tls Secret manifest(YAML or JSON)").String()
+	acmImportPassEnv       = acmImportCmd.Flag("passphrase-env", "Environment variable containing the passphrase of the private key, the PKCS#12 file or the keystore").String()
+	acmImportPassFile      = acmImportCmd.Flag("passphrase-file", "Path to a file containing the passphrase of the private key, the PKCS#12 file or the keystore").String()
+	acmImportCert          = acmImportCmd.Flag("cert", "The certificate to import").String()
+	acmImportChain         = acmImportCmd.Flag("chain", "The certificate chain").String()
+	acmImportPkey          = acmImportCmd.Flag("pkey", "The private key that matches the public key in the certificate").String()
+	acmImportName          = acmImportCmd.Flag("name", "The name tag value").String()
+	acmImportTags          = acmImportCmd.Flag("tags", "The tags to add to the certificate(key=value, comma separated)").String()
+	acmImportCABundle      = acmImportCmd.Flag("ca-bundle", "Path to CA certificates used to verify the certificate chain(default: system trust store)").String()
+	acmImportManifest      = acmImportCmd.Flag("manifest", "Path to a YAML manifest listing the certificates to import").String()
+	acmImportContinue      = acmImportCmd.Flag("continue-on-error", "Keep importing the other certificates in the manifest when one fails").Bool()
+	acmImportOnDuplicate   = acmImportCmd.Flag("on-duplicate", "What to do when ACM already has the same certificate(allow, skip, reimport, fail)").Default("allow").Enum("allow", "skip", "reimport", "fail")
 
 	// acm delete
 	acmDeleteCmd      = acmCmd.Command("delete", "Deletes an ACM Certificate and its associated private key")
@@ -44,30 +130,73 @@ var (
 	acmDeleteStatuses = acmDeleteCmd.Flag("cert-statuses", "The status or statuses on which to filter the list of ACM Certificates(comma separated)").Default("ALL").String()
 	acmDeleteMaxItems = acmDeleteCmd.Flag("max-items", "The total number of items to return in the command's output").Int()
 
+	// acm get
+	acmGetCmd      = acmCmd.Command("get", "Retrieves an ACM Certificate and its certificate chain")
+	acmGetArn      = acmGetCmd.Flag("arn", "String that contains the ARN of the ACM Certificate").Required().String()
+	acmGetFormat   = acmGetCmd.Flag("format", "The output format").Default("pem").Enum("pem", "der", "bundle")
+	acmGetCertOut  = acmGetCmd.Flag("cert-out", "Path to write the certificate to(default: stdout)").String()
+	acmGetChainOut = acmGetCmd.Flag("chain-out", "Path to write the certificate chain to. The pem format writes only the certificate without it and the der format requires it when there is a chain. The der format writes one file per certificate").String()
+
+	// acm tags
+	acmTagsCmd           = acmCmd.Command("tags", "Manages the tags of ACM Certificates")
+	acmTagsArns          = acmTagsCmd.Flag("arn", "String that contains the ARN of the ACM Certificate(repeatable). If omitted, the certificates matched by the filters are targeted").Strings()
+	acmTagsStatuses      = acmTagsCmd.Flag("cert-statuses", "The status or statuses on which to filter the list of ACM Certificates(comma separated)").Default("ALL").String()
+	acmTagsDomain        = acmTagsCmd.Flag("domain", "Only target certificates whose domain name or additional names match the domain(wildcard aware)").String()
+	acmTagsFilterTags    = acmTagsCmd.Flag("filter-tag", "Only target certificates that have the tag(key=value or key, repeatable)").Strings()
+	acmTagsType          = acmTagsCmd.Flag("type", "Only target certificates of the type").Enum("imported", "amazon-issued", "private")
+	acmTagsExpiresWithin = acmTagsCmd.Flag("expires-within", "Only target certificates that expire within the duration(e.g. 30d, 2w, 12h)").String()
+	acmTagsNoDryRun      = acmTagsCmd.Flag("no-dry-run", "Disable dry-run mode when tagging certificates matched by the filters").Bool()
+	// acm tags list
+	acmTagsListCmd = acmTagsCmd.Command("list", "Lists the tags of ACM Certificates")
+	// acm tags add
+	acmTagsAddCmd  = acmTagsCmd.Command("add", "Adds tags to ACM Certificates")
+	acmTagsAddTags = acmTagsAddCmd.Flag("tags", "The tags to add(key=value, comma separated)").Required().String()
+	// acm tags remove
+	acmTagsRemoveCmd  = acmTagsCmd.Command("remove", "Removes tags from ACM Certificates")
+	acmTagsRemoveTags = acmTagsRemoveCmd.Flag("tags", "The tags to remove(key or key=value, comma separated)").Required().String()
+	// acm tags replace
+	acmTagsReplaceCmd  = acmTagsCmd.Command("replace", "Replaces all tags of ACM Certificates")
+	acmTagsReplaceTags = acmTagsReplaceCmd.Flag("tags", "The tags to set(key=value, comma separated)").Required().String()
+
 	// iam
 	iamCmd = crtUtils.Command("iam", "AWS  Identity and Access Management (IAM)")
 	// iam list
-	iamListCmd        = iamCmd.Command("list", "Lists the server certificates stored in IAM that have the specified path prefix")
-	iamListMarker     = iamListCmd.Flag("marker", "Paginating results and only after you receive a response indicating that the results are truncated").String()
-	iamListMaxItems   = iamListCmd.Flag("max-items", "The total number of items to return").Int()
-	iamListPathPrefix = iamListCmd.Flag("path-prefix", "The path prefix for filtering the results").Default("/").String()
+	iamListCmd           = iamCmd.Command("list", "Lists the server certificates stored in IAM that have the specified path prefix")
+	iamListMarker        = iamListCmd.Flag("marker", "Paginating results and only after you receive a response indicating that the results are truncated").String()
+	iamListMaxItems      = iamListCmd.Flag("max-items", "The total number of items to return").Int()
+	iamListPathPrefix    = iamListCmd.Flag("path-prefix", "The path prefix for filtering the results").Default("/").String()
+	iamListExpiresWithin = iamListCmd.Flag("expires-within", "Only list server certificates that expire within the duration(e.g. 30d, 2w, 12h). With --marker or --max-items, only the returned page is filtered").String()
 
 	// iam upload
-	iamUploadCmd       = iamCmd.Command("upload", "Uploads a server certificate entity for the AWS account")
-	iamUploadCertPath  = iamUploadCmd.Flag("cert-path", "Path to certificate").String()
-	iamUploadChainPath = iamUploadCmd.Flag("chain-path", "Path tocertificate chain").String()
-	iamUploadPkeyPath  = iamUploadCmd.Flag("pkey-path", "Path to private key").String()
-	iamUploadCert      = iamUploadCmd.Flag("cert", "The contents of the public key certificate").String()
-	iamUploadChain     = iamUploadCmd.Flag("chain", "The contents of the certificate chain").String()
-	iamUploadPkey      = iamUploadCmd.Flag("pkey", "The contents of the private key").String()
-	iamUploadPath      = iamUploadCmd.Flag("path", "The path for the server certificate").Default("/").String()
-	iamUploadName      = iamUploadCmd.Flag("name", "The name for the server certificate").String()
+	iamUploadCmd           = iamCmd.Command("upload", "Uploads a server certificate entity for the AWS account")
+	iamUploadCertPath      = iamUploadCmd.Flag("cert-path", "Path to certificate").String()
+	iamUploadChainPath     = iamUploadCmd.Flag("chain-path", "Path tocertificate chain").String()
+	iamUploadPkeyPath      = iamUploadCmd.Flag("pkey-path", "Path to private key(- for stdin, env:NAME, fd:N or prompt: for a hidden prompt)").String()
+	iamUploadBundlePath    = iamUploadCmd.Flag("bundle-path", "Path to a PEM bundle or a directory containing the certificate, the intermediates and the root in any order").String()
+	iamUploadPKCS12Path    = iamUploadCmd.Flag("pkcs12-path", "Path to a PKCS#12/PFX file containing the certificate, the certificate chain and the private key").String()
+	iamUploadKeystorePath  = iamUploadCmd.Flag("keystore-path", "Path to a JKS or PKCS#12 keystore").String()
+	iamUploadKeystoreAlias = iamUploadCmd.Flag("keystore-alias", "The alias of the private key entry in the keystore(default: prompt when the keystore has several entries)").String()
+	iamUploadK8sSecretPath = iamUploadCmd.Flag("k8s-secret-path", "Path to a Kubernetes kubernetes.io/tls Secret manifest(YAML or JSON)").String()
+	iamUploadPassEnv       = iamUploadCmd.Flag("passphrase-env", "Environment variable containing the passphrase of the private key, the PKCS#12 file or the keystore").String()
+	iamUploadPassFile      = iamUploadCmd.Flag("passphrase-file", "Path to a file containing the passphrase of the private key, the PKCS#12 file or the keystore").String()
+	iamUploadCert          = iamUploadCmd.Flag("cert", "The contents of the public key certificate").String()
+	iamUploadChain         = iamUploadCmd.Flag("chain", "The contents of the certificate chain").String()
+	iamUploadPkey          = iamUploadCmd.Flag("pkey", "The contents of the private key").String()
+	iamUploadPath          = iamUploadCmd.Flag("path", "The path for the server certificate").Default("/").String()
+	iamUploadFor           = iamUploadCmd.Flag("for", "The service that uses the server certificate. cloudfront enforces the /cloudfront/ path prefix").Enum("cloudfront")
+	iamUploadName          = iamUploadCmd.Flag("name", "The name for the server certificate").String()
+	iamUploadTags          = iamUploadCmd.Flag("tags", "The tags to add to the server certificate(key=value, comma separated)").String()
+	iamUploadCABundle      = iamUploadCmd.Flag("ca-bundle", "Path to CA certificates used to verify the certificate chain(default: system trust store)").String()
+	iamUploadManifest      = iamUploadCmd.Flag("manifest", "Path to a YAML manifest listing the server certificates to upload").String()
+	iamUploadContinue      = iamUploadCmd.Flag("continue-on-error", "Keep uploading the other server certificates in the manifest when one fails").Bool()
+	iamUploadOnDuplicate   = iamUploadCmd.Flag("on-duplicate", "What to do when IAM already has the same certificate(allow, skip, fail)").Default("allow").Enum("allow", "skip", "fail")
 
 	// iam update
 	iamUpdateCmd     = iamCmd.Command("update", "Updates the name and/or the path of the specified server certificate stored in IAM")
 	iamUpdateNewPath = iamUpdateCmd.Flag("new-path", "The new path for the server certificate").String()
 	iamUpdateNewName = iamUpdateCmd.Flag("new-name", "The new name for the server certificate").String()
 	iamUpdateName    = iamUpdateCmd.Flag("name", "The name for the server certificate").String()
+	iamUpdateFor     = iamUpdateCmd.Flag("for", "The service that uses the server certificate. cloudfront enforces the /cloudfront/ path prefix").Enum("cloudfront")
 
 	// iam delete
 	iamDeleteCmd        = iamCmd.Command("delete", "Deletes the specified server certificate")
@@ -76,6 +205,41 @@ var (
 	iamDeleteMaxItems   = iamDeleteCmd.Flag("max-items", "The total number of items to return").Int()
 	iamDeletePathPrefix = iamDeleteCmd.Flag("path-prefix", "The path prefix for filtering the results").Default("/").String()
 
+	// iam get
+	iamGetCmd      = iamCmd.Command("get", "Retrieves a server certificate and its certificate chain")
+	iamGetName     = iamGetCmd.Flag("name", "The name of the server certificate").Required().String()
+	iamGetFormat   = iamGetCmd.Flag("format", "The output format").Default("pem").Enum("pem", "der", "bundle")
+	iamGetCertOut  = iamGetCmd.Flag("cert-out", "Path to write the certificate to(default: stdout)").String()
+	iamGetChainOut = iamGetCmd.Flag("chain-out", "Path to write the certificate chain to. The pem format writes only the certificate without it and the der format requires it when there is a chain. The der format writes one file per certificate").String()
+
+	// iam migrate-to-acm
+	iamMigrateCmd       = iamCmd.Command("migrate-to-acm", "Imports a server certificate into ACM and repoints the CloudFront distributions and load balancers that use it")
+	iamMigrateName      = iamMigrateCmd.Flag("name", "The name of the server certificate").Required().String()
+	iamMigratePkeyPath  = iamMigrateCmd.Flag("pkey-path", "Path to private key(- for stdin, env:NAME, fd:N or prompt: for a hidden prompt)").String()
+	iamMigratePkey      = iamMigrateCmd.Flag("pkey", "The private key that matches the public key in the server certificate").String()
+	iamMigratePassEnv   = iamMigrateCmd.Flag("passphrase-env", "Environment variable containing the passphrase of the private key").String()
+	iamMigratePassFile  = iamMigrateCmd.Flag("passphrase-file", "Path to a file containing the passphrase of the private key").String()
+	iamMigrateDeleteIAM = iamMigrateCmd.Flag("delete-iam", "Delete the server certificate after the migration").Bool()
+	iamMigrateNoDryRun  = iamMigrateCmd.Flag("no-dry-run", "Disable dry-run mode").Bool()
+
+	// iam tags
+	iamTagsCmd           = iamCmd.Command("tags", "Manages the tags of server certificates")
+	iamTagsNames         = iamTagsCmd.Flag("name", "The name of the server certificate(repeatable). If omitted, the server certificates matched by the filters are targeted").Strings()
+	iamTagsPathPrefix    = iamTagsCmd.Flag("path-prefix", "The path prefix for filtering the server certificates").Default("/").String()
+	iamTagsExpiresWithin = iamTagsCmd.Flag("expires-within", "Only target server certificates that expire within the duration(e.g. 30d, 2w, 12h)").String()
+	iamTagsNoDryRun      = iamTagsCmd.Flag("no-dry-run", "Disable dry-run mode when tagging server certificates matched by the filters").Bool()
+	// iam tags list
+	iamTagsListCmd = iamTagsCmd.Command("list", "Lists the tags of server certificates")
+	// iam tags add
+	iamTagsAddCmd  = iamTagsCmd.Command("add", "Adds tags to server certificates")
+	iamTagsAddTags = iamTagsAddCmd.Flag("tags", "The tags to add(key=value, comma separated)").Required().String()
+	// iam tags remove
+	iamTagsRemoveCmd  = iamTagsCmd.Command("remove", "Removes tags from server certificates")
+	iamTagsRemoveTags = iamTagsRemoveCmd.Flag("tags", "The tag keys to remove(comma separated)").Required().String()
+	// iam tags replace
+	iamTagsReplaceCmd  = iamTagsCmd.Command("replace", "Replaces all tags of server certificates")
+	iamTagsReplaceTags = iamTagsReplaceCmd.Flag("tags", "The tags to set(key=value, comma separated)").Required().String()
+
 	// cloudfront
 	cfCmd      = crtUtils.Command("cloudfront", "Amazon CloudFront")
 	cfMarker   = cfCmd.Flag("marker", "Paginating results and only after you receive a response indicating that the results are truncated").String()
@@ -105,6 +269,7 @@ var (
 	// elb list
 	elbListCmd        = elbCmd.Command("list", "Describes the specified  the load balancers")
 	elbListCertFilter = elbListCmd.Flag("cert", "String that contains the ARN of the ACM/IAM Certificate").PlaceHolder("ARN").String()
+	elbListRegions    = elbListCmd.Flag("regions", "The regions to query concurrently(all or comma separated, e.g. us-east-1,eu-west-1)").String()
 	// elb update
 	elbUpdateCmd  = elbCmd.Command("update", "Updates the specified a listener from the specified load balancer")
 	elbUpdateName = elbUpdateCmd.Flag("name", "The name of the load balancer").String()
@@ -113,15 +278,17 @@ var (
 
 	// elb bulk-update
 	elbBUpdateCmd         = elbCmd.Command("bulk-update", "Updates the specified listeners from the specified load balancer")
-	elbBUpdateSrcCertArn  = elbBUpdateCmd.Flag("source-cert-arn", "The ARN of the source ACM/IAM SSL Certificate").String()
-	elbBUpdateDestCertArn = elbBUpdateCmd.Flag("dest-cert-arn", "The ARN of the destination ACM/IAM SSL Certificate").String()
+	elbBUpdateSrcCertArn  = elbBUpdateCmd.Flag("source-cert-arn", "The ARN of the source ACM/IAM SSL Certificate(comma separated, one per region with --regions)").String()
+	elbBUpdateDestCertArn = elbBUpdateCmd.Flag("dest-cert-arn", "The ARN of the destination ACM/IAM SSL Certificate(comma separated, one per region with --regions)").String()
 	elbBUpdateNoDryRun    = elbBUpdateCmd.Flag("no-dry-run", "Disable dry-run mode").Bool()
+	elbBUpdateRegions     = elbBUpdateCmd.Flag("regions", "The regions to update(all or comma separated, e.g. us-east-1,eu-west-1)").String()
 
 	// alb
 	albCmd = crtUtils.Command("alb", "Application Load Balancing")
 	// alb list
 	albListCmd        = albCmd.Command("list", "Describes the specified load balancers")
 	albListCertFilter = albListCmd.Flag("cert", "The ARN of the ACM/IAM SSL Certificate").PlaceHolder("ARN").String()
+	albListRegions    = albListCmd.Flag("regions", "The regions to query concurrently(all or comma separated, e.g. us-east-1,eu-west-1)").String()
 
 	// alb update
 	albUpdateCmd  = albCmd.Command("update", "Updates the specified a listener from the specified load balancer")
@@ -130,9 +297,64 @@ var (
 
 	// alb bulk-update
 	albBUpdateCmd         = albCmd.Command("bulk-update", "Updates the specified listeners from the specified load balancer")
-	albBUpdateSrcCertArn  = albBUpdateCmd.Flag("source-cert-arn", "The ARN of the source ACM/IAM SSL Certificate").String()
-	albBUpdateDestCertArn = albBUpdateCmd.Flag("dest-cert-arn", "The ARN of the destination ACM/IAM SSL Certificate").String()
+	albBUpdateSrcCertArn  = albBUpdateCmd.Flag("source-cert-arn", "The ARN of the source ACM/IAM SSL Certificate(comma separated, one per region with --regions)").String()
+	albBUpdateDestCertArn = albBUpdateCmd.Flag("dest-cert-arn", "The ARN of the destination ACM/IAM SSL Certificate(comma separated, one per region with --regions)").String()
 	albBUpdateNoDryRun    = albBUpdateCmd.Flag("no-dry-run", "Disable dry-run mode").Bool()
+	albBUpdateRegions     = albBUpdateCmd.Flag("regions", "The regions to update(all or comma separated, e.g. us-east-1,eu-west-1)").String()
+
+	// sync-dir
+	syncDirCmd      = crtUtils.Command("sync-dir", "Re-imports the certificate in a directory(e.g. /etc/letsencrypt/live/<name>) when it changes")
+	syncDirDir      = syncDirCmd.Flag("dir", "The directory containing the certificate and the private key").Envar("RENEWED_LINEAGE").Required().String()
+	syncDirCertFile = syncDirCmd.Flag("cert-file", "The file name of the certificate and the certificate chain").Default("fullchain.pem").String()
+	syncDirKeyFile  = syncDirCmd.Flag("key-file", "The file name of the private key").Default("privkey.pem").String()
+	syncDirAcmArn   = syncDirCmd.Flag("acm-arn", "The ACM certificate to re-import into").String()
+	syncDirIAMName  = syncDirCmd.Flag("iam-name", "The base name of the server certificates(<name>-<not before>) to upload to IAM").String()
+	syncDirIAMPath  = syncDirCmd.Flag("iam-path", "The path for the server certificates").Default("/").String()
+	syncDirFor      = syncDirCmd.Flag("for", "The service that uses the server certificates. cloudfront enforces the /cloudfront/ path prefix").Enum("cloudfront")
+	syncDirCABundle = syncDirCmd.Flag("ca-bundle", "Path to CA certificates used to verify the certificate chain(default: system trust store)").String()
+	syncDirInterval = syncDirCmd.Flag("interval", "The interval to check the directory").Default("1m").Duration()
+	syncDirOnce     = syncDirCmd.Flag("once", "Syncs once and exits(e.g. as a certbot --deploy-hook)").Bool()
+	syncDirNoDryRun = syncDirCmd.Flag("no-dry-run", "Disable dry-run mode").Bool()
+
+	// acme
+	acmeCmd = crtUtils.Command("acme", "Issues certificates from an ACME CA(e.g. Let's Encrypt) into ACM")
+	// acme issue
+	acmeIssueCmd            = acmeCmd.Command("issue", "Obtains a certificate from an ACME CA and imports it into ACM")
+	acmeIssueDomains        = acmeIssueCmd.Flag("domain", "The domain name(repeatable)").Required().Strings()
+	acmeIssueDirectory      = acmeIssueCmd.Flag("directory", "The ACME directory URL").Default(certutils.LetsEncryptDirectoryURL).String()
+	acmeIssueDirectoryCA    = acmeIssueCmd.Flag("directory-ca-bundle", "Path to CA certificates used to verify the ACME server(e.g. the Pebble minica)").String()
+	acmeIssueAccountKeyPath = acmeIssueCmd.Flag("account-key-path", "Path to the ACME account key. Generated if it does not exist").Default("acme-account.pem").String()
+	acmeIssueEmail          = acmeIssueCmd.Flag("email", "The contact email address of the ACME account").String()
+	acmeIssueAgreeTOS       = acmeIssueCmd.Flag("agree-tos", "Agree to the terms of service of the ACME CA").Bool()
+	acmeIssueChallenge      = acmeIssueCmd.Flag("challenge", "The challenge type").Default("dns-01").Enum("dns-01", "http-01")
+	acmeIssueHostedZoneID   = acmeIssueCmd.Flag("hosted-zone-id", "The Route 53 hosted zone for dns-01(default: looked up by domain name)").String()
+	acmeIssueWebroot        = acmeIssueCmd.Flag("webroot", "The document root served on port 80 for http-01").String()
+	acmeIssueKeyType        = acmeIssueCmd.Flag("key-type", "The key type").Default("rsa").Enum("rsa", "ecdsa")
+	acmeIssueBits           = acmeIssueCmd.Flag("bits", "The key size(rsa: 2048, 3072, 4096, ecdsa: 256, 384. default: 2048 for rsa, 256 for ecdsa)").Int()
+	acmeIssueTimeout        = acmeIssueCmd.Flag("timeout", "How long to wait for the ACME CA").Default("5m").Duration()
+	acmeIssueCertOut        = acmeIssueCmd.Flag("cert-out", "Path to also write the certificate and the certificate chain to").String()
+	acmeIssueKeyOut         = acmeIssueCmd.Flag("key-out", "Path to also write the private key to").String()
+	acmeIssueAcmArn         = acmeIssueCmd.Flag("acm-arn", "Re-imports into the ACM certificate instead of importing a new one").String()
+	acmeIssueName           = acmeIssueCmd.Flag("name", "The name tag value").String()
+	acmeIssueTags           = acmeIssueCmd.Flag("tags", "The tags to add to the certificate(key=value, comma separated)").String()
+
+	// expiring
+	expiringCmd      = crtUtils.Command("expiring", "Reports the ACM and IAM certificates that expire soon and where they are attached")
+	expiringWithin   = expiringCmd.Flag("within", "Report certificates that expire within the duration(e.g. 720h, 30d, 4w). Exits with 1").Default("30d").String()
+	expiringCritical = expiringCmd.Flag("critical", "Certificates that expire within the duration are critical. Exits with 2").Default("7d").String()
+
+	// ics
+	icsCmd    = crtUtils.Command("ics", "Exports the expirations of the ACM and IAM certificates as an iCalendar feed")
+	icsAlarms = icsCmd.Flag("alarm", "Adds a reminder alarm the duration before the expiration to the events(repeatable, e.g. 30d, 7d, 12h)").Strings()
+
+	// serve-metrics
+	serveMetricsCmd      = crtUtils.Command("serve-metrics", "Serves Prometheus metrics of the certificates in ACM and IAM and where they are attached")
+	serveMetricsListen   = serveMetricsCmd.Flag("listen", "The address to listen on").Default(":9100").String()
+	serveMetricsInterval = serveMetricsCmd.Flag("refresh-interval", "The interval to refresh the inventory. Scrapes are served from the last refresh").Default("10m").Duration()
+
+	// verify
+	verifyCmd  = crtUtils.Command("verify", "Compares local certificates with the certificates in ACM/IAM and on CloudFront, ELB and ALB")
+	verifyPath = verifyCmd.Flag("path", "Path to a certificate or a directory of certificates").Required().String()
 )
 
 func main() {
@@ -145,8 +367,443 @@ func main() {
 
 	cmds := strings.Split(subCmd, " ")
 
+	if cmds[0] == "cert" {
+		switch cmds[1] {
+		case "inspect":
+			cm := certutils.NewCertificateManager()
+			cm.RefuseInlinePrivateKey = *refuseInlinePkey
+
+			err = cm.Load(certutils.CertificateSource{
+				Cert:     *certInspectCert,
+				CertPath: *certInspectCertPath,
+				Pkey:     *certInspectPkey,
+				PkeyPath: *certInspectPkeyPath,
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			info, err := certutils.InspectCertificate(cm.Cert, cm.Pkey)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if *certInspectFormat == "json" {
+				err = certutils.JSONCertificateInfo(info)
+				if err != nil {
+					log.Fatal(err)
+				}
+			} else {
+				certutils.ReadableCertificateInfo(info)
+			}
+		case "keygen":
+			err = certutils.CheckPrivateKeyBitLen(*certKeygenService, *certKeygenKeyType, keyBits(*certKeygenKeyType, *certKeygenBits))
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			key, err := certutils.GeneratePrivateKey(*certKeygenKeyType, keyBits(*certKeygenKeyType, *certKeygenBits))
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			data, err := certutils.EncodePrivateKey(key)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = certutils.WritePrivateKey(data, *certKeygenOut)
+			if err != nil {
+				log.Fatal(err)
+			}
+		case "csr":
+			subject, err := certutils.LoadCSRSubject(*certCSRConfig)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			subject = subject.Merge(certutils.CSRSubject{
+				CommonName:         *certCSRCommonName,
+				Organization:       *certCSROrg,
+				OrganizationalUnit: *certCSROrgUnit,
+				Country:            *certCSRCountry,
+				Province:           *certCSRProvince,
+				Locality:           *certCSRLocality,
+				DNSNames:           *certCSRDNSNames,
+			})
+
+			var key crypto.Signer
+			if *certCSRPkeyPath != "" {
+				data, err := certutils.GetCertificateData("", *certCSRPkeyPath)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				key, err = certutils.ParsePrivateKey(data, &certutils.PassphraseSource{})
+				if err != nil {
+					log.Fatal(err)
+				}
+			} else {
+				if *certCSRKeyOut == "" {
+					log.Fatal("--key-out is required to generate a new private key.")
+				}
+
+				err = certutils.CheckPrivateKeyBitLen(*certCSRService, *certCSRKeyType, keyBits(*certCSRKeyType, *certCSRBits))
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				key, err = certutils.GeneratePrivateKey(*certCSRKeyType, keyBits(*certCSRKeyType, *certCSRBits))
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				data, err := certutils.EncodePrivateKey(key)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				err = certutils.WritePrivateKey(data, *certCSRKeyOut)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			csr, err := certutils.CreateCSR(key, subject)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = certutils.WriteCSR(csr, *certCSROut)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		return
+	}
+
+	if cmds[0] == "dev-ca" {
+		switch cmds[1] {
+		case "init":
+			validFor, err := certutils.ParseDuration(*devCAInitValidFor)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			msg, err := certutils.InitDevCA(*devCAInitDir, *devCAInitCommonName, validFor, *devCAInitForce)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			fmt.Println(msg)
+		case "issue":
+			if *devCAIssueImport == "" && (*devCAIssueCertOut == "" || *devCAIssueKeyOut == "") {
+				log.Fatal("--cert-out and --key-out, or --import is required.")
+			}
+
+			service := *devCAIssueImport
+			if service == "" {
+				service = "acm"
+			}
+
+			err = certutils.CheckPrivateKeyBitLen(service, *devCAIssueKeyType, keyBits(*devCAIssueKeyType, *devCAIssueBits))
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			tags, err := certutils.SplitTags(*devCAIssueTags)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			name := *devCAIssueName
+			if name == "" {
+				name = certutils.DevCertificateName((*devCAIssueHostnames)[0])
+			}
+
+			if *devCAIssueImport == "acm" {
+				tags = append([]certutils.Tag{
+					certutils.Tag{
+						Key:   "Name",
+						Value: name,
+					},
+				}, tags...)
+			}
+
+			err = certutils.CheckTags(tags)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			validFor, err := certutils.ParseDuration(*devCAIssueValidFor)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			ca, err := certutils.LoadDevCA(*devCAIssueDir)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			cert, pkey, err := ca.Issue(*devCAIssueHostnames, validFor, *devCAIssueKeyType, keyBits(*devCAIssueKeyType, *devCAIssueBits))
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			cm := &certutils.CertificateManager{Cert: cert, Pkey: pkey}
+			err = cm.CheckPrivateKeyBitLen(service)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if *devCAIssueCertOut != "" {
+				err = certutils.ExportCertificate(cert, ca.CertPEM, "bundle", *devCAIssueCertOut, "")
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			if *devCAIssueKeyOut != "" {
+				err = certutils.WritePrivateKey(pkey, *devCAIssueKeyOut)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			if *devCAIssueImport == "" {
+				return
+			}
+
+			region := *awsRegion
+			if *devCAIssueImport == "iam" {
+				region = "us-east-1"
+			}
+
+			sess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, region, *awsProfile, *awsConfig, *awsCreds)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			switch *devCAIssueImport {
+			case "acm":
+				a := certutils.NewACM(sess)
+				arn, msg, err := a.Import(cert, ca.CertPEM, pkey)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				err = a.AddTags(arn, tags)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				fmt.Println(msg)
+			case "iam":
+				err = certutils.CheckIAMPath(*devCAIssuePath)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				i := certutils.NewIAM(sess)
+				msg, err := i.Upload(cert, ca.CertPEM, pkey, *devCAIssuePath, name, tags)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				fmt.Println(msg)
+			}
+		}
+
+		return
+	}
+
+	if cmds[0] == "sync-dir" {
+		notifier, err := loadNotifier()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if (*syncDirAcmArn == "") == (*syncDirIAMName == "") {
+			log.Fatal("--acm-arn or --iam-name but not both.")
+		}
+
+		caBundle, err := certutils.GetCertificateData("", *syncDirCABundle)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var ds *certutils.DirSync
+		if *syncDirAcmArn != "" {
+			region, err := certutils.ACMArnRegion(*syncDirAcmArn)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			sess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, region, *awsProfile, *awsConfig, *awsCreds)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			ds = certutils.NewACMDirSync(sess, *syncDirDir, *syncDirCertFile, *syncDirKeyFile, *syncDirAcmArn, caBundle)
+		} else {
+			path, err := certutils.IAMPathFor(*syncDirFor, *syncDirIAMPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			globalSess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, "us-east-1", *awsProfile, *awsConfig, *awsCreds)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			regionalSess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, *awsRegion, *awsProfile, *awsConfig, *awsCreds)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			ds = certutils.NewIAMDirSync(globalSess, regionalSess, *syncDirDir, *syncDirCertFile, *syncDirKeyFile, *syncDirIAMName, path, caBundle)
+		}
+
+		for {
+			msgs, changed, err := ds.Sync(!*syncDirNoDryRun)
+			for _, msg := range msgs {
+				fmt.Println(msg)
+			}
+
+			if changed || (err != nil && *syncDirNoDryRun) {
+				notify(notifier, certutils.RotatedEvent(ds.Target(), msgs, err))
+			}
+
+			if *syncDirOnce {
+				if err != nil {
+					log.Fatal(err)
+				}
+				return
+			}
+
+			if err != nil {
+				log.Println(err)
+			}
+
+			time.Sleep(*syncDirInterval)
+		}
+	}
+
+	if cmds[0] == "acme" {
+		tags, err := certutils.SplitTags(*acmeIssueTags)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if *acmeIssueName != "" {
+			tags = append([]certutils.Tag{
+				certutils.Tag{
+					Key:   "Name",
+					Value: *acmeIssueName,
+				},
+			}, tags...)
+		}
+
+		err = certutils.CheckTags(tags)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = certutils.CheckPrivateKeyBitLen("acm", *acmeIssueKeyType, keyBits(*acmeIssueKeyType, *acmeIssueBits))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		region := *awsRegion
+		if *acmeIssueAcmArn != "" {
+			region, err = certutils.ACMArnRegion(*acmeIssueAcmArn)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		sess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, region, *awsProfile, *awsConfig, *awsCreds)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		caBundle, err := certutils.GetCertificateData("", *acmeIssueDirectoryCA)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		issuer, err := certutils.NewACMEIssuer(sess, certutils.ACMEOptions{
+			DirectoryURL:   *acmeIssueDirectory,
+			CABundle:       caBundle,
+			AccountKeyPath: *acmeIssueAccountKeyPath,
+			Email:          *acmeIssueEmail,
+			AgreeTOS:       *acmeIssueAgreeTOS,
+			Challenge:      *acmeIssueChallenge,
+			HostedZoneID:   *acmeIssueHostedZoneID,
+			Webroot:        *acmeIssueWebroot,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		key, err := certutils.GeneratePrivateKey(*acmeIssueKeyType, keyBits(*acmeIssueKeyType, *acmeIssueBits))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		pkey, err := certutils.EncodePrivateKey(key)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), *acmeIssueTimeout)
+		cert, chain, err := issuer.Issue(ctx, *acmeIssueDomains, key)
+		cancel()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if *acmeIssueCertOut != "" {
+			err = certutils.ExportCertificate(cert, chain, "bundle", *acmeIssueCertOut, "")
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if *acmeIssueKeyOut != "" {
+			err = certutils.WritePrivateKey(pkey, *acmeIssueKeyOut)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		a := certutils.NewACM(sess)
+		arn := *acmeIssueAcmArn
+		var msg string
+		if arn != "" {
+			msg, err = a.Reimport(arn, cert, chain, pkey)
+		} else {
+			arn, msg, err = a.Import(cert, chain, pkey)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(tags) > 0 {
+			err = a.AddTags(arn, tags)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		fmt.Println(msg)
+
+		return
+	}
+
 	var region string
-	if cmds[0] == "iam" || cmds[0] == "cloudfront" {
+	if cmds[0] == "iam" || cmds[0] == "cloudfront" || cmds[0] == "verify" || cmds[0] == "expiring" || cmds[0] == "ics" || cmds[0] == "serve-metrics" {
 		region = "us-east-1"
 	} else {
 		region = *awsRegion
@@ -154,6 +811,9 @@ func main() {
 
 	sess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, region, *awsProfile, *awsConfig, *awsCreds)
 	if err != nil {
+		if cmds[0] == "expiring" {
+			expiringUnknown(err)
+		}
 		log.Fatal(err)
 	}
 
@@ -162,52 +822,155 @@ func main() {
 		a := certutils.NewACM(sess)
 		switch cmds[1] {
 		case "list":
-			out, err := a.List(*acmListStatuses, int64(*acmListMaxItems), "")
+			if *acmListInUse && *acmListUnused {
+				log.Fatal("--in-use or --unused but not both.")
+			}
+
+			expiresWithin, err := certutils.ParseDuration(*acmListExpiresWithin)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			tags, err := certutils.ParseTags(*acmListTags)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			var out []certutils.ACMDescription
+			if *acmListRegions != "" {
+				out, err = regionalSessions(sess, *acmListRegions).ListACM(*acmListStatuses, *acmListKeyTypes, int64(*acmListMaxItems))
+			} else {
+				out, err = a.List(*acmListStatuses, *acmListKeyTypes, int64(*acmListMaxItems), "")
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			out = certutils.FilterACMDescriptions(out, certutils.ACMListFilter{
+				ExpiresWithin: expiresWithin,
+				Domain:        *acmListDomain,
+				Tags:          tags,
+				InUse:         *acmListInUse,
+				Unused:        *acmListUnused,
+				Type:          *acmListType,
+			})
+
+			err = certutils.SortACMDescriptions(out, *acmListSortBy)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			a.ReadableList(out)
-		case "import":
-			err := certutils.CheckTagValuePattern(*acmImportName)
+			a.ReadableList(out, *acmListExpandNames)
+		case "import":
+			if *acmImportManifest != "" {
+				rejectWithManifest(map[string]string{
+					"--cert-path":       *acmImportCertPath,
+					"--chain-path":      *acmImportChainPath,
+					"--pkey-path":       *acmImportPkeyPath,
+					"--bundle-path":     *acmImportBundlePath,
+					"--pkcs12-path":     *acmImportPKCS12Path,
+					"--keystore-path":   *acmImportKeystorePath,
+					"--keystore-alias":  *acmImportKeystoreAlias,
+					"--k8s-secret-path": *acmImportK8sSecretPath,
+					"--passphrase-env":  *acmImportPassEnv,
+					"--passphrase-file": *acmImportPassFile,
+					"--cert":            *acmImportCert,
+					"--chain":           *acmImportChain,
+					"--pkey":            *acmImportPkey,
+					"--name":            *acmImportName,
+					"--tags":            *acmImportTags,
+				})
+
+				m, err := certutils.LoadManifest(*acmImportManifest)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				caBundle, err := certutils.GetCertificateData("", *acmImportCABundle)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				results := a.ImportManifest(m, certutils.ManifestOptions{
+					CABundle:               caBundle,
+					OnDuplicate:            *acmImportOnDuplicate,
+					ContinueOnError:        *acmImportContinue,
+					RefuseInlinePrivateKey: *refuseInlinePkey,
+				})
+
+				certutils.ReadableManifestResults(results)
+
+				if certutils.ManifestFailed(results) {
+					os.Exit(1)
+				}
+				break
+			}
+
+			tags, err := certutils.SplitTags(*acmImportTags)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if *acmImportName != "" {
+				tags = append([]certutils.Tag{
+					certutils.Tag{
+						Key:   "Name",
+						Value: *acmImportName,
+					},
+				}, tags...)
+			}
+
+			err = certutils.CheckTags(tags)
 			if err != nil {
 				log.Fatal(err)
 			}
 
 			cm := certutils.NewCertificateManager()
+			cm.RefuseInlinePrivateKey = *refuseInlinePkey
 
-			err = cm.LoadCertificate(*acmImportCert, *acmImportCertPath)
+			err = cm.Load(certutils.CertificateSource{
+				Cert:          *acmImportCert,
+				CertPath:      *acmImportCertPath,
+				Chain:         *acmImportChain,
+				ChainPath:     *acmImportChainPath,
+				Pkey:          *acmImportPkey,
+				PkeyPath:      *acmImportPkeyPath,
+				BundlePath:    *acmImportBundlePath,
+				PKCS12Path:    *acmImportPKCS12Path,
+				KeystorePath:  *acmImportKeystorePath,
+				KeystoreAlias: *acmImportKeystoreAlias,
+				K8sSecretPath: *acmImportK8sSecretPath,
+				Passphrase: &certutils.PassphraseSource{
+					Env:  *acmImportPassEnv,
+					File: *acmImportPassFile,
+				},
+			})
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			err = cm.LoadChain(*acmImportChain, *acmImportChainPath)
+			err = cm.CheckPrivateKeyBitLen("acm")
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			err = cm.LoadPrivateKey(*acmImportPkey, *acmImportPkeyPath)
+			caBundle, err := certutils.GetCertificateData("", *acmImportCABundle)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			err = cm.CheckPrivateKeyBitLen()
+			err = cm.VerifyChain(caBundle)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			arn, msg, err := a.Import(cm.Cert, cm.Chain, cm.Pkey)
+			arn, msg, changed, err := a.ImportWithPolicy(*acmImportOnDuplicate, cm.Cert, cm.Chain, cm.Pkey)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			if *acmImportName != "" {
-				err = a.AddTags(arn, []certutils.Tag{
-					certutils.Tag{
-						Key:   "Name",
-						Value: *acmImportName,
-					},
-				})
+			if changed && len(tags) > 0 {
+				err = a.AddTags(arn, tags)
 				if err != nil {
 					log.Fatal(err)
 				}
@@ -215,6 +978,11 @@ func main() {
 
 			fmt.Println(msg)
 		case "delete":
+			notifier, err := loadNotifier()
+			if err != nil {
+				log.Fatal(err)
+			}
+
 			arn := *acmDeleteArn
 			if arn == "" {
 				arns, targets, err := a.ListDeleteTargets(*acmDeleteStatuses, int64(*acmDeleteMaxItems), "")
@@ -236,54 +1004,234 @@ func main() {
 			}
 
 			fmt.Println(msg)
+			notify(notifier, certutils.DeletedEvent("ACM", arn))
+		case "get":
+			cert, chain, err := a.Get(*acmGetArn)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = certutils.ExportCertificate(cert, chain, *acmGetFormat, *acmGetCertOut, *acmGetChainOut)
+			if err != nil {
+				log.Fatal(err)
+			}
+		case "tags":
+			arns := *acmTagsArns
+			bulk := len(arns) == 0
+			if bulk {
+				expiresWithin, err := certutils.ParseDuration(*acmTagsExpiresWithin)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				filterTags, err := certutils.ParseTags(*acmTagsFilterTags)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				arns, err = a.ListArns(*acmTagsStatuses, certutils.ACMListFilter{
+					ExpiresWithin: expiresWithin,
+					Domain:        *acmTagsDomain,
+					Tags:          filterTags,
+					Type:          *acmTagsType,
+				})
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			if cmds[2] == "list" {
+				descs, err := a.ListTagDescriptions(arns)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				certutils.ReadableTagList(descs)
+				break
+			}
+
+			var tagsStr string
+			switch cmds[2] {
+			case "add":
+				tagsStr = *acmTagsAddTags
+			case "remove":
+				tagsStr = *acmTagsRemoveTags
+			case "replace":
+				tagsStr = *acmTagsReplaceTags
+			}
+
+			tags, err := certutils.SplitTags(tagsStr)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = certutils.CheckTags(tags)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			updates, err := a.BulkUpdateTags(cmds[2], arns, tags, bulk && !*acmTagsNoDryRun)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			for _, u := range updates {
+				fmt.Println(u)
+			}
 		}
 	case "iam":
 		i := certutils.NewIAM(sess)
 		switch cmds[1] {
 		case "list":
-			descs, err := i.List(*iamListMarker, int64(*iamListMaxItems), *iamListPathPrefix)
+			expiresWithin, err := certutils.ParseDuration(*iamListExpiresWithin)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			i.ReadableList(descs)
+			var descs []certutils.IAMDescription
+			if *iamListMarker == "" && *iamListMaxItems == 0 {
+				descs, err = i.ListAll(*iamListPathPrefix)
+			} else {
+				descs, err = i.List(*iamListMarker, int64(*iamListMaxItems), *iamListPathPrefix)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			i.ReadableList(certutils.FilterIAMDescriptions(descs, expiresWithin))
 		case "upload":
+			if *iamUploadManifest != "" {
+				path := *iamUploadPath
+				if path == "/" {
+					path = ""
+				}
+
+				rejectWithManifest(map[string]string{
+					"--cert-path":       *iamUploadCertPath,
+					"--chain-path":      *iamUploadChainPath,
+					"--pkey-path":       *iamUploadPkeyPath,
+					"--bundle-path":     *iamUploadBundlePath,
+					"--pkcs12-path":     *iamUploadPKCS12Path,
+					"--keystore-path":   *iamUploadKeystorePath,
+					"--keystore-alias":  *iamUploadKeystoreAlias,
+					"--k8s-secret-path": *iamUploadK8sSecretPath,
+					"--passphrase-env":  *iamUploadPassEnv,
+					"--passphrase-file": *iamUploadPassFile,
+					"--cert":            *iamUploadCert,
+					"--chain":           *iamUploadChain,
+					"--pkey":            *iamUploadPkey,
+					"--path":            path,
+					"--for":             *iamUploadFor,
+					"--name":            *iamUploadName,
+					"--tags":            *iamUploadTags,
+				})
+
+				m, err := certutils.LoadManifest(*iamUploadManifest)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				caBundle, err := certutils.GetCertificateData("", *iamUploadCABundle)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				results := i.UploadManifest(m, certutils.ManifestOptions{
+					CABundle:               caBundle,
+					OnDuplicate:            *iamUploadOnDuplicate,
+					ContinueOnError:        *iamUploadContinue,
+					RefuseInlinePrivateKey: *refuseInlinePkey,
+				})
+
+				certutils.ReadableManifestResults(results)
+
+				if certutils.ManifestFailed(results) {
+					os.Exit(1)
+				}
+				break
+			}
+
+			tags, err := certutils.SplitTags(*iamUploadTags)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = certutils.CheckTags(tags)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			path, err := certutils.IAMPathFor(*iamUploadFor, *iamUploadPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+
 			cm := certutils.NewCertificateManager()
+			cm.RefuseInlinePrivateKey = *refuseInlinePkey
 
-			err = cm.LoadCertificate(*iamUploadCert, *iamUploadCertPath)
+			err = cm.Load(certutils.CertificateSource{
+				Cert:          *iamUploadCert,
+				CertPath:      *iamUploadCertPath,
+				Chain:         *iamUploadChain,
+				ChainPath:     *iamUploadChainPath,
+				Pkey:          *iamUploadPkey,
+				PkeyPath:      *iamUploadPkeyPath,
+				BundlePath:    *iamUploadBundlePath,
+				PKCS12Path:    *iamUploadPKCS12Path,
+				KeystorePath:  *iamUploadKeystorePath,
+				KeystoreAlias: *iamUploadKeystoreAlias,
+				K8sSecretPath: *iamUploadK8sSecretPath,
+				Passphrase: &certutils.PassphraseSource{
+					Env:  *iamUploadPassEnv,
+					File: *iamUploadPassFile,
+				},
+			})
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			err = cm.LoadChain(*iamUploadChain, *iamUploadChainPath)
+			err = cm.CheckPrivateKeyBitLen("iam")
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			err = cm.LoadPrivateKey(*iamUploadPkey, *iamUploadPkeyPath)
+			caBundle, err := certutils.GetCertificateData("", *iamUploadCABundle)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			err = cm.CheckPrivateKeyBitLen()
+			err = cm.VerifyChain(caBundle)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			msg, err := i.Upload(cm.Cert, cm.Chain, cm.Pkey, *iamUploadPath, *iamUploadName)
+			msg, err := i.UploadWithPolicy(*iamUploadOnDuplicate, cm.Cert, cm.Chain, cm.Pkey, path, *iamUploadName, tags)
 			if err != nil {
 				log.Fatal(err)
 			}
 
 			fmt.Println(msg)
 		case "update":
-			msg, err := i.Update(*iamUpdateNewPath, *iamUpdateNewName, *iamUpdateName)
+			newPath := *iamUpdateNewPath
+			if newPath != "" || *iamUpdateFor != "" {
+				newPath, err = certutils.IAMPathFor(*iamUpdateFor, newPath)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			msg, err := i.Update(newPath, *iamUpdateNewName, *iamUpdateName)
 			if err != nil {
 				log.Fatal(err)
 			}
 
 			fmt.Println(msg)
 		case "delete":
+			notifier, err := loadNotifier()
+			if err != nil {
+				log.Fatal(err)
+			}
+
 			name := *iamDeleteName
 			if name == "" {
 				names, err := i.ListNames(*iamDeleteMarker, int64(*iamDeleteMaxItems), *iamDeletePathPrefix)
@@ -303,6 +1251,110 @@ func main() {
 			}
 
 			fmt.Println(msg)
+			notify(notifier, certutils.DeletedEvent("IAM", name))
+		case "get":
+			cert, chain, err := i.Get(*iamGetName)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = certutils.ExportCertificate(cert, chain, *iamGetFormat, *iamGetCertOut, *iamGetChainOut)
+			if err != nil {
+				log.Fatal(err)
+			}
+		case "migrate-to-acm":
+			notifier, err := loadNotifier()
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			cm := certutils.NewCertificateManager()
+			cm.RefuseInlinePrivateKey = *refuseInlinePkey
+
+			err = cm.LoadPrivateKey(*iamMigratePkey, *iamMigratePkeyPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			cm.Pkey, err = certutils.NormalizePrivateKey(cm.Pkey, &certutils.PassphraseSource{
+				Env:  *iamMigratePassEnv,
+				File: *iamMigratePassFile,
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			regionalSess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, *awsRegion, *awsProfile, *awsConfig, *awsCreds)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			m := certutils.NewIAMToACMMigration(sess, regionalSess)
+			msgs, err := m.Migrate(*iamMigrateName, cm.Pkey, *iamMigrateDeleteIAM, !*iamMigrateNoDryRun)
+			for _, msg := range msgs {
+				fmt.Println(msg)
+			}
+			if *iamMigrateNoDryRun {
+				notify(notifier, certutils.RotatedEvent(*iamMigrateName, msgs, err))
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+		case "tags":
+			names := *iamTagsNames
+			bulk := len(names) == 0
+			if bulk {
+				expiresWithin, err := certutils.ParseDuration(*iamTagsExpiresWithin)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				descs, err := i.ListAll(*iamTagsPathPrefix)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				names = certutils.IAMNames(certutils.FilterIAMDescriptions(descs, expiresWithin))
+			}
+
+			if cmds[2] == "list" {
+				descs, err := i.ListTagDescriptions(names)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				certutils.ReadableTagList(descs)
+				break
+			}
+
+			var tagsStr string
+			switch cmds[2] {
+			case "add":
+				tagsStr = *iamTagsAddTags
+			case "remove":
+				tagsStr = *iamTagsRemoveTags
+			case "replace":
+				tagsStr = *iamTagsReplaceTags
+			}
+
+			tags, err := certutils.SplitTags(tagsStr)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			err = certutils.CheckTags(tags)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			updates, err := i.BulkUpdateTags(cmds[2], names, tags, bulk && !*iamTagsNoDryRun)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			for _, u := range updates {
+				fmt.Println(u)
+			}
 		}
 	case "cloudfront":
 		cf := certutils.NewCloudFront(sess, *cfMarker, int64(*cfMaxItems))
@@ -335,6 +1387,11 @@ func main() {
 
 			fmt.Println(dist)
 		case "bulk-update":
+			notifier, err := loadNotifier()
+			if err != nil {
+				log.Fatal(err)
+			}
+
 			var service, srcCert, destCert string
 			if *cfBUpdateSrcACMArn == "" && *cfBUpdateSrcIAMId == "" {
 				log.Fatal("--source-acm-arn or --source-iam-id is required.")
@@ -359,6 +1416,9 @@ func main() {
 			}
 
 			dists, err := cf.BulkUpdate(service, srcCert, destCert, !*cfBUpdateNoDryRun)
+			if *cfBUpdateNoDryRun && (err != nil || len(dists) > 0) {
+				notify(notifier, certutils.RotatedEvent(destCert, dists, err))
+			}
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -371,7 +1431,12 @@ func main() {
 		e := certutils.NewELB(sess)
 		switch cmds[1] {
 		case "list":
-			descs, err := e.List(*elbListCertFilter)
+			var descs []certutils.ELBDescription
+			if *elbListRegions != "" {
+				descs, err = regionalSessions(sess, *elbListRegions).ListELB(*elbListCertFilter)
+			} else {
+				descs, err = e.List(*elbListCertFilter)
+			}
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -385,7 +1450,24 @@ func main() {
 
 			fmt.Println(update)
 		case "bulk-update":
-			updates, err := e.BulkUpdate(*elbBUpdateSrcCertArn, *elbBUpdateDestCertArn, !*elbBUpdateNoDryRun)
+			notifier, err := loadNotifier()
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			var updates []string
+			if *elbBUpdateRegions == "" && strings.Contains(*elbBUpdateSrcCertArn+*elbBUpdateDestCertArn, ",") {
+				log.Fatal("--regions is required to update with multiple certificate ARNs.")
+			}
+
+			if *elbBUpdateRegions != "" {
+				updates, err = regionalSessions(sess, *elbBUpdateRegions).BulkUpdateELB(splitArns(*elbBUpdateSrcCertArn), splitArns(*elbBUpdateDestCertArn), !*elbBUpdateNoDryRun)
+			} else {
+				updates, err = e.BulkUpdate(*elbBUpdateSrcCertArn, *elbBUpdateDestCertArn, !*elbBUpdateNoDryRun)
+			}
+			if *elbBUpdateNoDryRun && (err != nil || len(updates) > 0) {
+				notify(notifier, certutils.RotatedEvent(*elbBUpdateDestCertArn, updates, err))
+			}
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -398,7 +1480,12 @@ func main() {
 		alb := certutils.NewALB(sess)
 		switch cmds[1] {
 		case "list":
-			descs, err := alb.List(*albListCertFilter)
+			var descs []certutils.ALBDescription
+			if *albListRegions != "" {
+				descs, err = regionalSessions(sess, *albListRegions).ListALB(*albListCertFilter)
+			} else {
+				descs, err = alb.List(*albListCertFilter)
+			}
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -410,7 +1497,24 @@ func main() {
 				log.Fatal(err)
 			}
 		case "bulk-update":
-			albs, err := alb.BulkUpdate(*albBUpdateSrcCertArn, *albBUpdateDestCertArn, !*albBUpdateNoDryRun)
+			notifier, err := loadNotifier()
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			var albs []string
+			if *albBUpdateRegions == "" && strings.Contains(*albBUpdateSrcCertArn+*albBUpdateDestCertArn, ",") {
+				log.Fatal("--regions is required to update with multiple certificate ARNs.")
+			}
+
+			if *albBUpdateRegions != "" {
+				albs, err = regionalSessions(sess, *albBUpdateRegions).BulkUpdateALB(splitArns(*albBUpdateSrcCertArn), splitArns(*albBUpdateDestCertArn), !*albBUpdateNoDryRun)
+			} else {
+				albs, err = alb.BulkUpdate(*albBUpdateSrcCertArn, *albBUpdateDestCertArn, !*albBUpdateNoDryRun)
+			}
+			if *albBUpdateNoDryRun && (err != nil || len(albs) > 0) {
+				notify(notifier, certutils.RotatedEvent(*albBUpdateDestCertArn, albs, err))
+			}
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -419,5 +1523,157 @@ func main() {
 				fmt.Println(alb)
 			}
 		}
+	case "verify":
+		locals, err := certutils.LoadLocalCertificates(*verifyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		regionalSess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, *awsRegion, *awsProfile, *awsConfig, *awsCreds)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		inv := certutils.NewInventory(sess, regionalSess)
+		results, err := inv.Verify(locals)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		certutils.ReadableVerifyResults(results)
+
+		if certutils.HasDrift(results) {
+			os.Exit(1)
+		}
+	case "expiring":
+		notifier, err := loadNotifier()
+		if err != nil {
+			expiringUnknown(err)
+		}
+
+		within, err := certutils.ParseDuration(*expiringWithin)
+		if err != nil {
+			expiringUnknown(err)
+		}
+
+		critical, err := certutils.ParseDuration(*expiringCritical)
+		if err != nil {
+			expiringUnknown(err)
+		}
+
+		regionalSess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, *awsRegion, *awsProfile, *awsConfig, *awsCreds)
+		if err != nil {
+			expiringUnknown(err)
+		}
+
+		inv := certutils.NewInventory(sess, regionalSess)
+		entries, err := inv.Expiring(within, critical)
+		if err != nil {
+			expiringUnknown(err)
+		}
+
+		certutils.ReadableExpiryEntries(entries)
+
+		for _, entry := range entries {
+			notify(notifier, entry.Event())
+		}
+
+		os.Exit(certutils.ExpiryExitCode(entries))
+	case "ics":
+		alarms := make([]time.Duration, 0, len(*icsAlarms))
+		for _, a := range *icsAlarms {
+			alarm, err := certutils.ParseDuration(a)
+			if err != nil {
+				log.Fatal(err)
+			}
+			alarms = append(alarms, alarm)
+		}
+
+		regionalSess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, *awsRegion, *awsProfile, *awsConfig, *awsCreds)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		entries, err := certutils.NewInventory(sess, regionalSess).AllExpiries()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = certutils.WriteExpiryCalendar(os.Stdout, entries, alarms)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "serve-metrics":
+		regionalSess, err := certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, *awsRegion, *awsProfile, *awsConfig, *awsCreds)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		m := certutils.NewMetricsExporter(certutils.NewInventory(sess, regionalSess), *serveMetricsInterval)
+		log.Fatal(m.Serve(*serveMetricsListen))
+	}
+}
+
+func rejectWithManifest(flags map[string]string) {
+	names := make([]string, 0)
+	for name, val := range flags {
+		if val != "" {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) > 0 {
+		sort.Strings(names)
+		log.Fatalf("%s can not be used with --manifest. Set them in the manifest", strings.Join(names, ", "))
+	}
+}
+
+func loadNotifier() (*certutils.Notifier, error) {
+	return certutils.LoadNotifier(*notifyConfig, func(region string) (*session.Session, error) {
+		return certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, region, *awsProfile, *awsConfig, *awsCreds)
+	})
+}
+
+func expiringUnknown(err error) {
+	log.Println(err)
+	os.Exit(certutils.ExpiryUnknown)
+}
+
+func notify(notifier *certutils.Notifier, event certutils.NotificationEvent) {
+	err := notifier.Notify(event)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func regionalSessions(sess *session.Session, regions string) *certutils.RegionalSessions {
+	names, err := certutils.ParseRegions(sess, regions)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rs, err := certutils.NewRegionalSessions(names, func(region string) (*session.Session, error) {
+		return certutils.NewAWSSession(*awsAccessKeyID, *awsSecretAccessKey, *awsArn, *awsToken, region, *awsProfile, *awsConfig, *awsCreds)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return rs
+}
+
+func splitArns(arns string) []string {
+	if arns == "" {
+		return []string{}
+	}
+
+	return strings.Split(arns, ",")
+}
+
+func keyBits(keyType string, bits int) int {
+	if bits == 0 {
+		return certutils.DefaultKeyBits(keyType)
 	}
+
+	return bits
 }
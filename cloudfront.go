@@ -23,6 +23,7 @@ type CFDistribution struct {
 	id         string
 	domain     string
 	cert       string
+	certID     string
 	aliasesStr string
 	aliases    []string
 }
@@ -52,19 +53,42 @@ func createCFListDistributionsInput(marker string, maxItems int64) *cloudfront.L
 	return dinput
 }
 
+func (cf *CloudFront) listDistributionSummaries() ([]*cloudfront.DistributionSummary, error) {
+	input := createCFListDistributionsInput(cf.marker, cf.maxItems)
+	if cf.maxItems > 0 {
+		out, err := cf.client.ListDistributions(input)
+		if err != nil {
+			return []*cloudfront.DistributionSummary{}, err
+		}
+
+		return out.DistributionList.Items, nil
+	}
+
+	summaries := make([]*cloudfront.DistributionSummary, 0)
+	err := cf.client.ListDistributionsPages(input, func(out *cloudfront.ListDistributionsOutput, lastPage bool) bool {
+		summaries = append(summaries, out.DistributionList.Items...)
+		return true
+	})
+	if err != nil {
+		return []*cloudfront.DistributionSummary{}, err
+	}
+
+	return summaries, nil
+}
+
 func (cf *CloudFront) getDistributions(certFilter, aliasesFilter string) ([]CFDistribution, error) {
-	out, err := cf.client.ListDistributions(createCFListDistributionsInput(cf.marker, cf.maxItems))
+	summaries, err := cf.listDistributionSummaries()
 	if err != nil {
 		return []CFDistribution{}, err
 	}
 
-	iamDescs, err := cf.iamClient.ListMap("", int64(0), "")
+	iamDescs, err := cf.iamClient.ListMap("")
 	if err != nil {
 		return []CFDistribution{}, err
 	}
 
-	dists := make([]CFDistribution, 0, len(out.DistributionList.Items))
-	for _, summary := range out.DistributionList.Items {
+	dists := make([]CFDistribution, 0, len(summaries))
+	for _, summary := range summaries {
 		dist := CFDistribution{}
 
 		vCert := summary.ViewerCertificate
@@ -81,6 +105,7 @@ func (cf *CloudFront) getDistributions(certFilter, aliasesFilter string) ([]CFDi
 		if certFilter != "" && dist.cert != certFilter {
 			continue
 		}
+		dist.certID = dist.cert
 
 		if iamCert != "" {
 			dist.cert = iamCert
@@ -165,6 +190,10 @@ func (cf *CloudFront) GetDistribution(id string) (*cloudfront.GetDistributionOut
 	return cf.client.GetDistribution(createGetDistributionInput(id))
 }
 
+func (cf *CloudFront) WaitDeployed(id string) error {
+	return cf.client.WaitUntilDistributionDeployed(createGetDistributionInput(id))
+}
+
 func getCertificate(vc *cloudfront.ViewerCertificate) string {
 	if aws.StringValue(vc.ACMCertificateArn) != "" {
 		return aws.StringValue(vc.ACMCertificateArn)
@@ -176,7 +205,32 @@ func getCertificate(vc *cloudfront.ViewerCertificate) string {
 	return ""
 }
 
+func (cf *CloudFront) checkIAMCertificate(id string) error {
+	iamDescs, err := cf.iamClient.ListMap("")
+	if err != nil {
+		return err
+	}
+
+	desc, ok := iamDescs[id]
+	if !ok {
+		return fmt.Errorf("IAM certificate %s not found", id)
+	}
+
+	if !strings.HasPrefix(desc.path, cloudFrontIAMPathPrefix) {
+		return fmt.Errorf("IAM certificate %s (%s) is uploaded under %s, but CloudFront only uses server certificates under %s. Move it with: aws-cert-utils iam update --name %s --for cloudfront", id, desc.name, desc.path, cloudFrontIAMPathPrefix, desc.name)
+	}
+
+	return nil
+}
+
 func (cf *CloudFront) Update(id, service, cert string) (string, error) {
+	if service == "iam" {
+		err := cf.checkIAMCertificate(cert)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	distOut, err := cf.GetDistribution(id)
 	if err != nil {
 		return "", err
@@ -199,6 +253,13 @@ func cfUpdateMsg(id, aliases, src, dest string) string {
 }
 
 func (cf *CloudFront) BulkUpdate(service, srcCert, destCert string, dryRun bool) ([]string, error) {
+	if service == "iam" {
+		err := cf.checkIAMCertificate(destCert)
+		if err != nil {
+			return []string{}, err
+		}
+	}
+
 	dists, err := cf.getDistributions(srcCert, "")
 	if err != nil {
 		return []string{}, err
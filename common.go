@@ -6,12 +6,17 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/olekukonko/tablewriter"
 	"github.com/tkuchiki/aws-sdk-go-config"
 	survey "gopkg.in/AlecAivazis/survey.v1"
 )
@@ -19,15 +24,57 @@ import (
 const minPrivateKeyBitLength = 1024
 const maxPrivateKeyBitLength = 2048
 
+var keyCompatibility = map[string]map[string][]int{
+	"acm": {
+		"RSA":   {minPrivateKeyBitLength, maxPrivateKeyBitLength, 3072, 4096},
+		"ECDSA": {256, 384},
+	},
+	"iam": {
+		"RSA":   {minPrivateKeyBitLength, maxPrivateKeyBitLength},
+		"ECDSA": {256, 384},
+	},
+	"cloudfront": {
+		"RSA":   {minPrivateKeyBitLength, maxPrivateKeyBitLength},
+		"ECDSA": {256},
+	},
+}
+
+const minTagKeyLength = 1
+const maxTagKeyLength = 128
+const maxTagValueLength = 256
+
+const tagPattern = `[\p{L}\p{Z}\p{N}_.:\/=+\-@]*`
+
 type Tag struct {
 	Key   string
 	Value string
 }
 
+type TagDescription struct {
+	resource string
+	tags     []Tag
+}
+
+type CertificateSource struct {
+	Cert          string
+	CertPath      string
+	Chain         string
+	ChainPath     string
+	Pkey          string
+	PkeyPath      string
+	BundlePath    string
+	PKCS12Path    string
+	KeystorePath  string
+	KeystoreAlias string
+	K8sSecretPath string
+	Passphrase    *PassphraseSource
+}
+
 type CertificateManager struct {
-	Cert  []byte
-	Chain []byte
-	Pkey  []byte
+	Cert                   []byte
+	Chain                  []byte
+	Pkey                   []byte
+	RefuseInlinePrivateKey bool
 }
 
 func NewCertificateManager() *CertificateManager {
@@ -41,6 +88,13 @@ func (cm *CertificateManager) LoadCertificate(cert, certPath string) error {
 }
 
 func (cm *CertificateManager) LoadPrivateKey(pkey, pkeyPath string) error {
+	if pkey != "" && pkeyPath == "" {
+		if cm.RefuseInlinePrivateKey {
+			return fmt.Errorf("Refusing the private key passed as a flag value. Use a file, -, env:NAME, fd:N or prompt: instead")
+		}
+		log.Println("Warning: the private key passed as a flag value is visible in the process list and the shell history. Use a file, -, env:NAME, fd:N or prompt: instead")
+	}
+
 	var err error
 	cm.Pkey, err = GetCertificateData(pkey, pkeyPath)
 	return err
@@ -52,12 +106,79 @@ func (cm *CertificateManager) LoadChain(chain, chainPath string) error {
 	return err
 }
 
-func (cm *CertificateManager) CheckPrivateKeyBitLen() error {
-	bit, err := PrivateKeyBitLen(cm.Cert, cm.Pkey)
+func (cm *CertificateManager) Load(src CertificateSource) error {
+	passphrase := src.Passphrase
+	if passphrase == nil {
+		passphrase = &PassphraseSource{}
+	}
+
+	if src.PKCS12Path != "" || src.KeystorePath != "" || src.K8sSecretPath != "" {
+		if src.Cert != "" || src.CertPath != "" || src.Chain != "" || src.ChainPath != "" || src.Pkey != "" || src.PkeyPath != "" || src.BundlePath != "" {
+			return fmt.Errorf("The PKCS#12 file, the keystore and the Kubernetes secret can not be used with the certificate, the certificate chain, the private key or the bundle")
+		}
+
+		var err error
+		switch {
+		case src.PKCS12Path != "":
+			err = cm.LoadPKCS12(src.PKCS12Path, passphrase)
+		case src.KeystorePath != "":
+			err = cm.LoadKeystore(src.KeystorePath, src.KeystoreAlias, passphrase)
+		default:
+			err = cm.LoadKubernetesSecret(src.K8sSecretPath)
+		}
+		if err != nil {
+			return err
+		}
+
+		return cm.Normalize(passphrase)
+	}
+
+	err := cm.LoadPrivateKey(src.Pkey, src.PkeyPath)
+	if err != nil {
+		return err
+	}
+
+	cm.Pkey, err = NormalizePrivateKey(cm.Pkey, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if src.BundlePath != "" {
+		if src.Cert != "" || src.CertPath != "" || src.Chain != "" || src.ChainPath != "" {
+			return fmt.Errorf("The bundle can not be used with the certificate or the certificate chain")
+		}
+
+		err = cm.LoadBundle(src.BundlePath, passphrase)
+		if err != nil {
+			return err
+		}
+
+		return cm.Normalize(passphrase)
+	}
+
+	err = cm.LoadCertificate(src.Cert, src.CertPath)
+	if err != nil {
+		return err
+	}
+
+	err = cm.LoadChain(src.Chain, src.ChainPath)
+	if err != nil {
+		return err
+	}
+
+	return cm.Normalize(passphrase)
+}
+
+func (cm *CertificateManager) CheckPrivateKeyBitLen(service string) error {
+	keyType, bit, err := privateKeyTypeAndBitLen(cm.Cert, cm.Pkey)
 	if err != nil {
 		return err
 	}
-	return CheckPrivateKeyBitLen(bit)
+	return CheckPrivateKeyBitLen(service, keyType, bit)
+}
+
+func (cm *CertificateManager) VerifyChain(caBundle []byte) error {
+	return VerifyChain(cm.Cert, cm.Chain, caBundle)
 }
 
 func NewAWSSession(accessKey, secretKey, arn, token, region, profile, config, creds string) (*session.Session, error) {
@@ -86,37 +207,56 @@ func readFile(fpath string) ([]byte, error) {
 
 func GetCertificateData(data, fpath string) ([]byte, error) {
 	if fpath != "" {
-		return readFile(fpath)
+		return readSource(fpath)
 	}
 
 	return []byte(data), nil
 }
 
 func PrivateKeyBitLen(certBlock, keyBlock []byte) (int, error) {
+	_, bit, err := privateKeyTypeAndBitLen(certBlock, keyBlock)
+	return bit, err
+}
+
+func privateKeyTypeAndBitLen(certBlock, keyBlock []byte) (string, int, error) {
 	cert, err := tls.X509KeyPair(certBlock, keyBlock)
 	if err != nil {
-		return 0, err
+		return "", 0, err
 	}
 
-	var bit int
 	switch privateKey := cert.PrivateKey.(type) {
 	case *rsa.PrivateKey:
-		bit = privateKey.N.BitLen()
+		return "RSA", privateKey.N.BitLen(), nil
 	case *ecdsa.PrivateKey:
-		bit = privateKey.Curve.Params().BitSize
-	default:
-		return 0, fmt.Errorf("unsupported private key")
+		return "ECDSA", privateKey.Curve.Params().BitSize, nil
 	}
 
-	return bit, nil
+	return "", 0, fmt.Errorf("unsupported private key")
 }
 
-func CheckPrivateKeyBitLen(bit int) error {
-	if bit > maxPrivateKeyBitLength {
-		return fmt.Errorf("Invalid private key length (%d bit). AWS supports %d and %d bit RSA private key", bit, minPrivateKeyBitLength, maxPrivateKeyBitLength)
+func CheckPrivateKeyBitLen(service, keyType string, bit int) error {
+	rules, ok := keyCompatibility[service]
+	if !ok {
+		return fmt.Errorf("Invalid service %s. Supported services are acm, iam and cloudfront", service)
 	}
 
-	return nil
+	keyType = strings.ToUpper(keyType)
+	for _, b := range rules[keyType] {
+		if b == bit {
+			return nil
+		}
+	}
+
+	supported := make([]string, 0)
+	for t, bits := range rules {
+		for _, b := range bits {
+			supported = append(supported, fmt.Sprintf("%s %d", t, b))
+		}
+	}
+
+	sort.Strings(supported)
+
+	return fmt.Errorf("Invalid private key (%s %d bit). %s supports %s bit private key", keyType, bit, strings.ToUpper(service), strings.Join(supported, ", "))
 }
 
 func SplitStatuses(s string) []string {
@@ -142,20 +282,320 @@ func SplitStatuses(s string) []string {
 	return statuses
 }
 
+func SplitKeyTypes(s string) []string {
+	if s == "" || strings.ToUpper(s) == "ALL" {
+		return []string{
+			"RSA_1024",
+			"RSA_2048",
+			"RSA_3072",
+			"RSA_4096",
+			"EC_prime256v1",
+			"EC_secp384r1",
+			"EC_secp521r1",
+		}
+	}
+
+	splited := strings.Split(s, ",")
+
+	keyTypes := make([]string, 0, len(splited))
+	for _, val := range splited {
+		keyTypes = append(keyTypes, strings.TrimSpace(val))
+	}
+
+	return keyTypes
+}
+
+func ParseTag(s string) (Tag, error) {
+	kv := strings.SplitN(s, "=", 2)
+	if kv[0] == "" {
+		return Tag{}, fmt.Errorf("Invalid tag %s. Tag format is key=value", s)
+	}
+
+	tag := Tag{Key: kv[0]}
+	if len(kv) == 2 {
+		tag.Value = kv[1]
+	}
+
+	return tag, nil
+}
+
+func ParseTags(strs []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(strs))
+	for _, s := range strs {
+		tag, err := ParseTag(s)
+		if err != nil {
+			return []Tag{}, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+func ParseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+
+	for suffix, unit := range units {
+		if !strings.HasSuffix(s, suffix) {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSuffix(s, suffix))
+		if err != nil {
+			return 0, fmt.Errorf("Invalid duration %s", s)
+		}
+
+		return time.Duration(n) * unit, nil
+	}
+
+	return time.ParseDuration(s)
+}
+
+func ExpiresWithin(notAfter time.Time, d time.Duration) bool {
+	return notAfter.Before(time.Now().Add(d))
+}
+
+func MatchDomain(pattern, name string) bool {
+	pattern = strings.ToLower(pattern)
+	name = strings.ToLower(name)
+
+	if pattern == name {
+		return true
+	}
+
+	wildcardMatch := func(wildcard, host string) bool {
+		if !strings.HasPrefix(wildcard, "*.") {
+			return false
+		}
+
+		i := strings.Index(host, ".")
+		if i <= 0 {
+			return false
+		}
+
+		return host[i+1:] == wildcard[2:]
+	}
+
+	return wildcardMatch(name, pattern) || wildcardMatch(pattern, name)
+}
+
+func SplitTags(s string) ([]Tag, error) {
+	if s == "" {
+		return []Tag{}, nil
+	}
+
+	splited := strings.Split(s, ",")
+
+	strs := make([]string, 0, len(splited))
+	for _, val := range splited {
+		strs = append(strs, strings.TrimSpace(val))
+	}
+
+	return ParseTags(strs)
+}
+
+func CheckTagKeyPattern(key string) error {
+	if len(key) < minTagKeyLength || len([]rune(key)) > maxTagKeyLength {
+		return fmt.Errorf("Invalid tag key length (%s). Tag key must be %d to %d characters", key, minTagKeyLength, maxTagKeyLength)
+	}
+
+	if strings.HasPrefix(strings.ToLower(key), "aws:") {
+		return fmt.Errorf("Invalid tag key (%s). Tag key must not start with aws:", key)
+	}
+
+	re := regexp.MustCompile(tagPattern)
+
+	group := re.FindStringSubmatch(key)
+	if len(group) > 0 && group[0] == key {
+		return nil
+	}
+
+	return fmt.Errorf("Invalid tag key. Tag key supports %s", tagPattern)
+}
+
 func CheckTagValuePattern(val string) error {
 	if val == "" {
 		return nil
 	}
 
-	pattern := `[\p{L}\p{Z}\p{N}_.:\/=+\-@]*`
-	re := regexp.MustCompile(pattern)
+	if len([]rune(val)) > maxTagValueLength {
+		return fmt.Errorf("Invalid tag value length (%s). Tag value must be up to %d characters", val, maxTagValueLength)
+	}
+
+	re := regexp.MustCompile(tagPattern)
 
 	group := re.FindStringSubmatch(val)
 	if len(group) > 0 && group[0] == val {
 		return nil
 	}
 
-	return fmt.Errorf("Invalid tag value. Tag value supports %s", pattern)
+	return fmt.Errorf("Invalid tag value. Tag value supports %s", tagPattern)
+}
+
+func CheckTags(tags []Tag) error {
+	for _, tag := range tags {
+		err := CheckTagKeyPattern(tag.Key)
+		if err != nil {
+			return err
+		}
+
+		err = CheckTagValuePattern(tag.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type tagger interface {
+	ListTags(resource string) ([]Tag, error)
+	AddTags(resource string, tags []Tag) error
+	RemoveTags(resource string, tags []Tag) error
+}
+
+func replaceTags(t tagger, resource string, tags []Tag) error {
+	current, err := t.ListTags(resource)
+	if err != nil {
+		return err
+	}
+
+	removes := staleTags(current, tags)
+	if len(removes) > 0 {
+		err = t.RemoveTags(resource, removes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	return t.AddTags(resource, tags)
+}
+
+func updateTags(t tagger, action, resource string, tags []Tag) (string, error) {
+	var err error
+	switch action {
+	case "add":
+		err = t.AddTags(resource, tags)
+	case "remove":
+		err = t.RemoveTags(resource, tags)
+	case "replace":
+		err = replaceTags(t, resource, tags)
+	default:
+		return "", fmt.Errorf("Invalid tag action %s", action)
+	}
+
+	return tagUpdateMsg(action, resource, tags), err
+}
+
+func bulkUpdateTags(t tagger, action string, resources []string, tags []Tag, dryRun bool) ([]string, error) {
+	updates := make([]string, 0)
+	if dryRun {
+		updates = append(updates, dryRunMsg()...)
+	}
+
+	for _, resource := range resources {
+		if dryRun {
+			updates = append(updates, tagUpdateMsg(action, resource, tags))
+			continue
+		}
+
+		msg, err := updateTags(t, action, resource, tags)
+		if err != nil {
+			return []string{}, err
+		}
+		updates = append(updates, msg)
+	}
+
+	return updates, nil
+}
+
+func listTagDescriptions(t tagger, resources []string) ([]TagDescription, error) {
+	descs := make([]TagDescription, 0, len(resources))
+	for _, resource := range resources {
+		tags, err := t.ListTags(resource)
+		if err != nil {
+			return []TagDescription{}, err
+		}
+
+		descs = append(descs, TagDescription{
+			resource: resource,
+			tags:     tags,
+		})
+	}
+
+	return descs, nil
+}
+
+func staleTags(current, tags []Tag) []Tag {
+	keys := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		keys[tag.Key] = true
+	}
+
+	stale := make([]Tag, 0)
+	for _, tag := range current {
+		if !keys[tag.Key] {
+			stale = append(stale, tag)
+		}
+	}
+
+	return stale
+}
+
+func tagsString(tags []Tag) string {
+	strs := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag.Value == "" {
+			strs = append(strs, tag.Key)
+			continue
+		}
+		strs = append(strs, fmt.Sprintf("%s=%s", tag.Key, tag.Value))
+	}
+
+	return strings.Join(strs, ",")
+}
+
+func tagUpdateMsg(action, resource string, tags []Tag) string {
+	switch action {
+	case "add":
+		return fmt.Sprintf("Added tags %s to %s", tagsString(tags), resource)
+	case "remove":
+		return fmt.Sprintf("Removed tags %s from %s", tagsString(tags), resource)
+	}
+
+	return fmt.Sprintf("Replaced tags of %s with %s", resource, tagsString(tags))
+}
+
+func ReadableTagList(descs []TagDescription) {
+	table := tablewriter.NewWriter(os.Stdout)
+
+	table.SetHeader([]string{"Resource", "Key", "Value"})
+	table.SetAutoMergeCells(true)
+	table.SetRowLine(true)
+
+	for _, desc := range descs {
+		if len(desc.tags) == 0 {
+			table.Append([]string{desc.resource, "", ""})
+			continue
+		}
+
+		for _, tag := range desc.tags {
+			table.Append([]string{desc.resource, tag.Key, tag.Value})
+		}
+	}
+
+	table.Render()
 }
 
 func Choice(choices []string, msg string, pagesize int) string {
@@ -170,6 +610,16 @@ func Choice(choices []string, msg string, pagesize int) string {
 	return val
 }
 
+func Password(msg string) string {
+	val := ""
+	prompt := &survey.Password{
+		Message: msg,
+	}
+	survey.AskOne(prompt, &val, nil)
+
+	return val
+}
+
 func toFlatten(strs []*string) string {
 	return strings.Join(aws.StringValueSlice(strs), " ")
 }
@@ -0,0 +1,178 @@
+package certutils
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	devCACertFile = "ca.pem"
+	devCAKeyFile  = "ca-key.pem"
+)
+
+type DevCA struct {
+	Cert    *x509.Certificate
+	CertPEM []byte
+	Key     crypto.Signer
+}
+
+func serialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func InitDevCA(dir, commonName string, validity time.Duration, force bool) (string, error) {
+	certPath := filepath.Join(dir, devCACertFile)
+	keyPath := filepath.Join(dir, devCAKeyFile)
+
+	if !force {
+		if _, err := os.Stat(certPath); err == nil {
+			return "", fmt.Errorf("%s already exists. Use --force to overwrite", certPath)
+		}
+	}
+
+	key, err := GeneratePrivateKey("rsa", maxPrivateKeyBitLength)
+	if err != nil {
+		return "", err
+	}
+
+	serial, err := serialNumber()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-1 * time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return "", err
+	}
+
+	pkey, err := encodePrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	err = ioutil.WriteFile(keyPath, pkey, 0600)
+	if err != nil {
+		return "", err
+	}
+
+	err = ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Created CA %s (%s, %s)", commonName, certPath, keyPath), nil
+}
+
+func LoadDevCA(dir string) (*DevCA, error) {
+	certPEM, err := readFile(filepath.Join(dir, devCACertFile))
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := ParseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cert.IsCA {
+		return nil, fmt.Errorf("%s is not a CA certificate", filepath.Join(dir, devCACertFile))
+	}
+
+	keyPEM, err := readFile(filepath.Join(dir, devCAKeyFile))
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParsePrivateKey(keyPEM, &PassphraseSource{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DevCA{
+		Cert:    cert,
+		CertPEM: certPEM,
+		Key:     key,
+	}, nil
+}
+
+func (ca *DevCA) Issue(hostnames []string, validity time.Duration, keyType string, bit int) ([]byte, []byte, error) {
+	if len(hostnames) == 0 {
+		return []byte{}, []byte{}, fmt.Errorf("At least one hostname is required")
+	}
+
+	key, err := GeneratePrivateKey(keyType, bit)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	serial, err := serialNumber()
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(validity)
+	if notAfter.After(ca.Cert.NotAfter) {
+		notAfter = ca.Cert.NotAfter
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: hostnames[0]},
+		NotBefore:    now.Add(-1 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	for _, h := range hostnames {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	pkey, err := encodePrivateKey(key)
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pkey, nil
+}
+
+func DevCertificateName(hostname string) string {
+	return strings.Replace(hostname, "*", "wildcard", -1)
+}
@@ -0,0 +1,172 @@
+package certutils
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/acm"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+const (
+	OnDuplicateAllow    = "allow"
+	OnDuplicateSkip     = "skip"
+	OnDuplicateReimport = "reimport"
+	OnDuplicateFail     = "fail"
+)
+
+func certificateFingerprint(data []byte) (string, error) {
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		return "", err
+	}
+
+	return SHA256Fingerprint(cert), nil
+}
+
+func duplicateError(fp, id string) error {
+	return fmt.Errorf("Duplicate certificate (SHA-256 %s). %s has the same certificate", fp, id)
+}
+
+func (a *ACM) FindDuplicate(data []byte) (string, error) {
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		return "", err
+	}
+	fp := SHA256Fingerprint(cert)
+
+	names := map[string]bool{cert.Subject.CommonName: true}
+	for _, name := range cert.DNSNames {
+		names[name] = true
+	}
+
+	candidates := make([]string, 0)
+	err = a.client.ListCertificatesPages(createACMListCertificatesInput([]string{}, SplitKeyTypes("all"), 0, ""),
+		func(out *acm.ListCertificatesOutput, lastPage bool) bool {
+			for _, summary := range out.CertificateSummaryList {
+				if names[aws.StringValue(summary.DomainName)] {
+					candidates = append(candidates, aws.StringValue(summary.CertificateArn))
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return "", err
+	}
+
+	for _, arn := range candidates {
+		body, _, err := a.Get(arn)
+		if err != nil {
+			// certificates that are not issued yet have no body
+			continue
+		}
+
+		efp, err := certificateFingerprint(body)
+		if err != nil {
+			continue
+		}
+
+		if efp == fp {
+			return arn, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (a *ACM) ImportWithPolicy(policy string, cert, chain, pkey []byte) (string, string, bool, error) {
+	if policy == "" || policy == OnDuplicateAllow {
+		arn, msg, err := a.Import(cert, chain, pkey)
+		return arn, msg, err == nil, err
+	}
+
+	existing, err := a.FindDuplicate(cert)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	if existing == "" {
+		arn, msg, err := a.Import(cert, chain, pkey)
+		return arn, msg, err == nil, err
+	}
+
+	switch policy {
+	case OnDuplicateSkip:
+		return existing, fmt.Sprintf("Skipped %s (same certificate)", existing), false, nil
+	case OnDuplicateReimport:
+		msg, err := a.Reimport(existing, cert, chain, pkey)
+		return existing, msg, err == nil, err
+	case OnDuplicateFail:
+		fp, _ := certificateFingerprint(cert)
+		return existing, "", false, duplicateError(fp, existing)
+	}
+
+	return "", "", false, fmt.Errorf("Invalid policy %s. Supported policies are allow, skip, reimport and fail", policy)
+}
+
+func (i *IAM) FindDuplicate(data []byte) (IAMDescription, bool, error) {
+	cert, err := ParseCertificate(data)
+	if err != nil {
+		return IAMDescription{}, false, err
+	}
+	fp := SHA256Fingerprint(cert)
+
+	candidates := make([]IAMDescription, 0)
+	err = i.client.ListServerCertificatesPages(createIAMListServerCertificatesInput("", 0, ""),
+		func(out *iam.ListServerCertificatesOutput, lastPage bool) bool {
+			for _, metadata := range out.ServerCertificateMetadataList {
+				if aws.TimeValue(metadata.Expiration).Equal(cert.NotAfter) {
+					candidates = append(candidates, newIAMDescription(metadata))
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return IAMDescription{}, false, err
+	}
+
+	for _, desc := range candidates {
+		body, _, err := i.Get(desc.name)
+		if err != nil {
+			return IAMDescription{}, false, err
+		}
+
+		efp, err := certificateFingerprint(body)
+		if err != nil {
+			continue
+		}
+
+		if efp == fp {
+			return desc, true, nil
+		}
+	}
+
+	return IAMDescription{}, false, nil
+}
+
+func (i *IAM) UploadWithPolicy(policy string, cert, chain, pkey []byte, path, name string, tags []Tag) (string, error) {
+	if policy == "" || policy == OnDuplicateAllow {
+		return i.Upload(cert, chain, pkey, path, name, tags)
+	}
+
+	existing, found, err := i.FindDuplicate(cert)
+	if err != nil {
+		return "", err
+	}
+
+	if !found {
+		return i.Upload(cert, chain, pkey, path, name, tags)
+	}
+
+	switch policy {
+	case OnDuplicateSkip:
+		return fmt.Sprintf("Skipped %s %s (same certificate)", existing.name, existing.arn), nil
+	case OnDuplicateReimport:
+		return "", fmt.Errorf("IAM server certificates can not be re-imported in place. %s already has the certificate", existing.name)
+	case OnDuplicateFail:
+		fp, _ := certificateFingerprint(cert)
+		return "", duplicateError(fp, existing.name)
+	}
+
+	return "", fmt.Errorf("Invalid policy %s. Supported policies are allow, skip, reimport and fail", policy)
+}
@@ -17,6 +17,7 @@ type ELB struct {
 type ELBDescription struct {
 	name    string
 	dnsname string
+	region  string
 	certs   []ELBCertificate
 }
 
@@ -132,18 +133,25 @@ func (e *ELB) BulkUpdate(srcCertArn, destCertArn string, dryRun bool) ([]string,
 		updates = append(updates, dryRunMsg()...)
 	}
 
+	msgs, err := e.updateListeners(descs, srcCertArn, destCertArn, dryRun)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return append(updates, msgs...), nil
+}
+
+func (e *ELB) updateListeners(descs []ELBDescription, srcCertArn, destCertArn string, dryRun bool) ([]string, error) {
+	updates := make([]string, 0)
 	for _, desc := range descs {
 		for _, cert := range desc.certs {
-			if dryRun {
-				updates = append(updates, elbUpdateMsg(desc.name, cert.port, srcCertArn, destCertArn))
-			} else {
+			if !dryRun {
 				_, err := e.client.SetLoadBalancerListenerSSLCertificate(createELBSetLoadBalancerListenerSSLCertificateInput(desc.name, cert.port, destCertArn))
-
 				if err != nil {
-					return []string{}, err
+					return updates, err
 				}
-				updates = append(updates, elbUpdateMsg(desc.name, cert.port, srcCertArn, destCertArn))
 			}
+			updates = append(updates, regionMsg(desc.region, elbUpdateMsg(desc.name, cert.port, srcCertArn, destCertArn)))
 		}
 	}
 
@@ -153,13 +161,26 @@ func (e *ELB) BulkUpdate(srcCertArn, destCertArn string, dryRun bool) ([]string,
 func (e *ELB) ReadableList(descs []ELBDescription) {
 	table := tablewriter.NewWriter(os.Stdout)
 
-	table.SetHeader([]string{"Name", "Port", "Listener SSL Certificate"})
+	withRegion := false
+	for _, desc := range descs {
+		withRegion = withRegion || desc.region != ""
+	}
+
+	header := []string{"Name", "Port", "Listener SSL Certificate"}
+	if withRegion {
+		header = append([]string{"Region"}, header...)
+	}
+	table.SetHeader(header)
 	table.SetAutoMergeCells(true)
 	table.SetRowLine(true)
 
 	for _, desc := range descs {
 		for _, cert := range desc.certs {
-			table.Append([]string{desc.name, fmt.Sprint(cert.port), cert.arn})
+			row := []string{desc.name, fmt.Sprint(cert.port), cert.arn}
+			if withRegion {
+				row = append([]string{desc.region}, row...)
+			}
+			table.Append(row)
 		}
 	}
 
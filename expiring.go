@@ -0,0 +1,143 @@
+package certutils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	ExpiryOK       = 0
+	ExpiryWarning  = 1
+	ExpiryCritical = 2
+	ExpiryUnknown  = 3
+)
+
+type ExpiryEntry struct {
+	cert        InventoryCertificate
+	days        int
+	level       int
+	attachments []Attachment
+}
+
+func daysRemaining(notAfter time.Time) int {
+	d := time.Until(notAfter)
+	days := int(d / (24 * time.Hour))
+	if d < 0 && d%(24*time.Hour) != 0 {
+		days--
+	}
+
+	return days
+}
+
+func expiryLevelString(level int) string {
+	switch level {
+	case ExpiryCritical:
+		return "CRITICAL"
+	case ExpiryWarning:
+		return "WARNING"
+	}
+
+	return "OK"
+}
+
+func (inv *Inventory) Expiring(within, critical time.Duration) ([]ExpiryEntry, error) {
+	return inv.expiryEntries(within, critical, false)
+}
+
+func (inv *Inventory) AllExpiries() ([]ExpiryEntry, error) {
+	return inv.expiryEntries(0, 0, true)
+}
+
+func (inv *Inventory) expiryEntries(within, critical time.Duration, all bool) ([]ExpiryEntry, error) {
+	certs, err := inv.Certificates(false)
+	if err != nil {
+		return []ExpiryEntry{}, err
+	}
+
+	attachments, err := inv.Attachments()
+	if err != nil {
+		return []ExpiryEntry{}, err
+	}
+
+	entries := make([]ExpiryEntry, 0)
+	for _, c := range certs {
+		if c.NotAfter.IsZero() || (!all && !ExpiresWithin(c.NotAfter, within)) {
+			continue
+		}
+
+		err = inv.fillDomains(&c)
+		if err != nil {
+			return []ExpiryEntry{}, err
+		}
+
+		level := ExpiryOK
+		if ExpiresWithin(c.NotAfter, critical) {
+			level = ExpiryCritical
+		} else if ExpiresWithin(c.NotAfter, within) {
+			level = ExpiryWarning
+		}
+
+		entries = append(entries, ExpiryEntry{
+			cert:        c,
+			days:        daysRemaining(c.NotAfter),
+			level:       level,
+			attachments: c.attachments(attachments),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].cert.NotAfter.Before(entries[j].cert.NotAfter)
+	})
+
+	return entries, nil
+}
+
+func ExpiryExitCode(entries []ExpiryEntry) int {
+	code := ExpiryOK
+	for _, e := range entries {
+		if e.level > code {
+			code = e.level
+		}
+	}
+
+	return code
+}
+
+func (e ExpiryEntry) consumers() []string {
+	consumers := make([]string, 0, len(e.attachments))
+	for _, a := range e.attachments {
+		consumers = append(consumers, fmt.Sprintf("%s %s", a.Consumer, a.Endpoint))
+	}
+
+	return consumers
+}
+
+func ReadableExpiryEntries(entries []ExpiryEntry) {
+	table := tablewriter.NewWriter(os.Stdout)
+
+	table.SetHeader([]string{"Level", "Days", "Not After", "Store", "Certificate", "Name tag", "Domain Name", "Attached To"})
+	table.SetAutoWrapText(false)
+	table.SetRowLine(true)
+
+	for _, e := range entries {
+		table.Append([]string{
+			expiryLevelString(e.level),
+			fmt.Sprint(e.days),
+			e.cert.NotAfter.String(),
+			e.cert.Store,
+			e.cert.ID,
+			e.cert.NameTag,
+			strings.Join(e.cert.Domains, " "),
+			strings.Join(e.consumers(), "\n"),
+		})
+	}
+
+	table.Render()
+
+	fmt.Printf("%d certificate(s)\n", len(entries))
+}
@@ -0,0 +1,101 @@
+package certutils
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func pemBlocks(data []byte, blockType string) []*pem.Block {
+	blocks := make([]*pem.Block, 0)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if blockType != "" && block.Type != blockType {
+			continue
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+func encodePEMBlocks(blocks []*pem.Block) []byte {
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		pem.Encode(&buf, block)
+	}
+
+	return buf.Bytes()
+}
+
+func indexedPath(fpath string, i int) string {
+	ext := filepath.Ext(fpath)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(fpath, ext), i, ext)
+}
+
+func writeData(data []byte, fpath string) error {
+	if fpath == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(fpath, data, 0644)
+}
+
+func ExportCertificate(cert, chain []byte, format, certPath, chainPath string) error {
+	certBlocks := pemBlocks(cert, "CERTIFICATE")
+	if len(certBlocks) == 0 {
+		return fmt.Errorf("Certificate not found")
+	}
+	chainBlocks := pemBlocks(chain, "CERTIFICATE")
+
+	switch format {
+	case "pem":
+		err := writeData(encodePEMBlocks(certBlocks[:1]), certPath)
+		if err != nil {
+			return err
+		}
+
+		if chainPath == "" {
+			return nil
+		}
+
+		return writeData(encodePEMBlocks(chainBlocks), chainPath)
+	case "der":
+		if len(chainBlocks) > 0 && chainPath == "" {
+			return fmt.Errorf("The certificate has a chain. --chain-out is required for the der format")
+		}
+
+		err := writeData(certBlocks[0].Bytes, certPath)
+		if err != nil {
+			return err
+		}
+
+		for i, block := range chainBlocks {
+			err = writeData(block.Bytes, indexedPath(chainPath, i+1))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case "bundle":
+		if chainPath != "" {
+			return fmt.Errorf("--chain-out can not be used with the bundle format")
+		}
+
+		return writeData(encodePEMBlocks(append(certBlocks, chainBlocks...)), certPath)
+	}
+
+	return fmt.Errorf("Invalid format %s. Supported formats are pem, der and bundle", format)
+}
@@ -3,6 +3,9 @@ package certutils
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -10,15 +13,20 @@ import (
 	"github.com/olekukonko/tablewriter"
 )
 
+const maxIAMPathLength = 512
+const cloudFrontIAMPathPrefix = "/cloudfront/"
+
 type IAM struct {
 	client *iam.IAM
 }
 
 type IAMDescription struct {
-	name string
-	id   string
-	path string
-	arn  string
+	name       string
+	id         string
+	path       string
+	arn        string
+	uploadDate time.Time
+	expiration time.Time
 }
 
 func NewIAM(sess *session.Session) *IAM {
@@ -27,7 +35,22 @@ func NewIAM(sess *session.Session) *IAM {
 	}
 }
 
-func createIAMUploadServerCertificateInput(body, chain, pkey, path, name string) *iam.UploadServerCertificateInput {
+func toIAMTags(tags []Tag) []*iam.Tag {
+	iamTags := make([]*iam.Tag, 0, len(tags))
+
+	for _, t := range tags {
+		iamTag := &iam.Tag{
+			Key:   aws.String(t.Key),
+			Value: aws.String(t.Value),
+		}
+
+		iamTags = append(iamTags, iamTag)
+	}
+
+	return iamTags
+}
+
+func createIAMUploadServerCertificateInput(body, chain, pkey, path, name string, tags []Tag) *iam.UploadServerCertificateInput {
 	input := &iam.UploadServerCertificateInput{}
 
 	input.SetCertificateBody(body)
@@ -36,13 +59,48 @@ func createIAMUploadServerCertificateInput(body, chain, pkey, path, name string)
 	input.SetPrivateKey(pkey)
 	input.SetServerCertificateName(name)
 
+	if len(tags) > 0 {
+		input.SetTags(toIAMTags(tags))
+	}
+
 	return input
 }
 
-func (i *IAM) Upload(cert, chain, pkey []byte, path, name string) (string, error) {
-	out, err := i.client.UploadServerCertificate(createIAMUploadServerCertificateInput(string(cert), string(chain), string(pkey), path, name))
+func (i *IAM) Upload(cert, chain, pkey []byte, path, name string, tags []Tag) (string, error) {
+	out, err := i.client.UploadServerCertificate(createIAMUploadServerCertificateInput(string(cert), string(chain), string(pkey), path, name, tags))
+	if err != nil {
+		return "", err
+	}
 
-	return fmt.Sprintf("Uploaded %s %s", name, *out.ServerCertificateMetadata.Arn), err
+	return fmt.Sprintf("Uploaded %s %s", name, *out.ServerCertificateMetadata.Arn), nil
+}
+
+func createIAMGetServerCertificateInput(name string) *iam.GetServerCertificateInput {
+	input := &iam.GetServerCertificateInput{}
+
+	input.SetServerCertificateName(name)
+
+	return input
+}
+
+func (i *IAM) Describe(name string) (IAMDescription, error) {
+	out, err := i.client.GetServerCertificate(createIAMGetServerCertificateInput(name))
+	if err != nil {
+		return IAMDescription{}, err
+	}
+
+	return newIAMDescription(out.ServerCertificate.ServerCertificateMetadata), nil
+}
+
+func (i *IAM) Get(name string) ([]byte, []byte, error) {
+	out, err := i.client.GetServerCertificate(createIAMGetServerCertificateInput(name))
+	if err != nil {
+		return []byte{}, []byte{}, err
+	}
+
+	cert := out.ServerCertificate
+
+	return []byte(aws.StringValue(cert.CertificateBody)), []byte(aws.StringValue(cert.CertificateChain)), nil
 }
 
 func createIAMListServerCertificatesInput(marker string, maxItems int64, path string) *iam.ListServerCertificatesInput {
@@ -63,6 +121,17 @@ func createIAMListServerCertificatesInput(marker string, maxItems int64, path st
 	return input
 }
 
+func newIAMDescription(metadata *iam.ServerCertificateMetadata) IAMDescription {
+	return IAMDescription{
+		name:       *metadata.ServerCertificateName,
+		id:         *metadata.ServerCertificateId,
+		path:       *metadata.Path,
+		arn:        *metadata.Arn,
+		uploadDate: aws.TimeValue(metadata.UploadDate),
+		expiration: aws.TimeValue(metadata.Expiration),
+	}
+}
+
 func (i *IAM) List(marker string, maxItems int64, path string) ([]IAMDescription, error) {
 	out, err := i.client.ListServerCertificates(createIAMListServerCertificatesInput(marker, maxItems, path))
 	if err != nil {
@@ -71,35 +140,66 @@ func (i *IAM) List(marker string, maxItems int64, path string) ([]IAMDescription
 
 	descs := make([]IAMDescription, 0, len(out.ServerCertificateMetadataList))
 	for _, metadata := range out.ServerCertificateMetadataList {
-		desc := IAMDescription{
-			name: *metadata.ServerCertificateName,
-			id:   *metadata.ServerCertificateId,
-			path: *metadata.Path,
-			arn:  *metadata.Arn,
-		}
-		descs = append(descs, desc)
+		descs = append(descs, newIAMDescription(metadata))
 	}
 
 	return descs, err
 }
 
-func (i *IAM) ListMap(marker string, maxItems int64, path string) (map[string]IAMDescription, error) {
-	out, err := i.client.ListServerCertificates(createIAMListServerCertificatesInput(marker, maxItems, path))
+func (i *IAM) ListAll(path string) ([]IAMDescription, error) {
+	descs := make([]IAMDescription, 0)
+	err := i.client.ListServerCertificatesPages(createIAMListServerCertificatesInput("", 0, path),
+		func(out *iam.ListServerCertificatesOutput, lastPage bool) bool {
+			for _, metadata := range out.ServerCertificateMetadataList {
+				descs = append(descs, newIAMDescription(metadata))
+			}
+			return true
+		})
+	if err != nil {
+		return []IAMDescription{}, err
+	}
+
+	return descs, nil
+}
+
+func (i *IAM) ListMap(path string) (map[string]IAMDescription, error) {
+	descs, err := i.ListAll(path)
 	if err != nil {
 		return map[string]IAMDescription{}, err
 	}
 
-	certs := make(map[string]IAMDescription, 0)
-	for _, metadata := range out.ServerCertificateMetadataList {
-		certs[*metadata.ServerCertificateId] = IAMDescription{
-			name: *metadata.ServerCertificateName,
-			id:   *metadata.ServerCertificateId,
-			path: *metadata.Path,
-			arn:  *metadata.Arn,
+	certs := make(map[string]IAMDescription, len(descs))
+	for _, desc := range descs {
+		certs[desc.id] = desc
+	}
+
+	return certs, nil
+}
+
+func FilterIAMDescriptions(descs []IAMDescription, expiresWithin time.Duration) []IAMDescription {
+	if expiresWithin <= 0 {
+		return descs
+	}
+
+	filtered := make([]IAMDescription, 0, len(descs))
+	for _, desc := range descs {
+		if !ExpiresWithin(desc.expiration, expiresWithin) {
+			continue
 		}
+
+		filtered = append(filtered, desc)
 	}
 
-	return certs, err
+	return filtered
+}
+
+func IAMNames(descs []IAMDescription) []string {
+	names := make([]string, 0, len(descs))
+	for _, desc := range descs {
+		names = append(names, desc.name)
+	}
+
+	return names
 }
 
 func (i *IAM) ListNames(marker string, maxItems int64, path string) ([]string, error) {
@@ -108,19 +208,54 @@ func (i *IAM) ListNames(marker string, maxItems int64, path string) ([]string, e
 		return []string{}, err
 	}
 
-	names := make([]string, 0, len(descs))
-	for _, desc := range descs {
-		names = append(names, desc.name)
+	return IAMNames(descs), err
+}
+
+func CheckIAMPath(path string) error {
+	if len(path) > maxIAMPathLength {
+		return fmt.Errorf("Invalid path length (%d). Path must be up to %d characters", len(path), maxIAMPathLength)
+	}
+
+	re := regexp.MustCompile(`^(/|/[\x{21}-\x{7E}]+/)$`)
+	if !re.MatchString(path) {
+		return fmt.Errorf("Invalid path %s. Path must begin and end with / and contain only printable ASCII characters other than spaces", path)
+	}
+
+	return nil
+}
+
+func IAMPathFor(service, path string) (string, error) {
+	if service != "cloudfront" {
+		return path, CheckIAMPath(path)
+	}
+
+	if path == "" || path == "/" {
+		path = cloudFrontIAMPathPrefix
+	}
+
+	err := CheckIAMPath(path)
+	if err != nil {
+		return "", err
 	}
 
-	return names, err
+	if !strings.HasPrefix(path, cloudFrontIAMPathPrefix) {
+		return "", fmt.Errorf("Invalid path %s. CloudFront requires server certificates under %s", path, cloudFrontIAMPathPrefix)
+	}
+
+	return path, nil
 }
 
 func createIAMUpdateServerCertificateInput(newPath, newName, name string) *iam.UpdateServerCertificateInput {
 	input := &iam.UpdateServerCertificateInput{}
 
-	input.SetNewPath(newPath)
-	input.SetNewServerCertificateName(newName)
+	if newPath != "" {
+		input.SetNewPath(newPath)
+	}
+
+	if newName != "" {
+		input.SetNewServerCertificateName(newName)
+	}
+
 	input.SetServerCertificateName(name)
 
 	return input
@@ -129,6 +264,10 @@ func createIAMUpdateServerCertificateInput(newPath, newName, name string) *iam.U
 func (i *IAM) Update(newPath, newName, name string) (string, error) {
 	_, err := i.client.UpdateServerCertificate(createIAMUpdateServerCertificateInput(newPath, newName, name))
 
+	if newName == "" {
+		return fmt.Sprintf("Updated %s -> %s", name, newPath), err
+	}
+
 	return fmt.Sprintf("Updated %s -> %s", name, newName), err
 }
 
@@ -139,13 +278,89 @@ func (i *IAM) Delete(name string) (string, error) {
 	return fmt.Sprintf("Deleted %s", name), err
 }
 
+func createIAMListServerCertificateTagsInput(name string) *iam.ListServerCertificateTagsInput {
+	input := &iam.ListServerCertificateTagsInput{}
+
+	input.SetServerCertificateName(name)
+
+	return input
+}
+
+func (i *IAM) ListTags(name string) ([]Tag, error) {
+	out, err := i.client.ListServerCertificateTags(createIAMListServerCertificateTagsInput(name))
+	if err != nil {
+		return []Tag{}, err
+	}
+
+	tags := make([]Tag, 0, len(out.Tags))
+	for _, tag := range out.Tags {
+		tags = append(tags, Tag{
+			Key:   aws.StringValue(tag.Key),
+			Value: aws.StringValue(tag.Value),
+		})
+	}
+
+	return tags, nil
+}
+
+func createIAMTagServerCertificateInput(name string, tags []Tag) *iam.TagServerCertificateInput {
+	input := &iam.TagServerCertificateInput{}
+
+	input.SetServerCertificateName(name)
+	input.SetTags(toIAMTags(tags))
+
+	return input
+}
+
+func (i *IAM) AddTags(name string, tags []Tag) error {
+	_, err := i.client.TagServerCertificate(createIAMTagServerCertificateInput(name, tags))
+
+	return err
+}
+
+func createIAMUntagServerCertificateInput(name string, tags []Tag) *iam.UntagServerCertificateInput {
+	input := &iam.UntagServerCertificateInput{}
+
+	keys := make([]string, 0, len(tags))
+	for _, t := range tags {
+		keys = append(keys, t.Key)
+	}
+
+	input.SetServerCertificateName(name)
+	input.SetTagKeys(aws.StringSlice(keys))
+
+	return input
+}
+
+func (i *IAM) RemoveTags(name string, tags []Tag) error {
+	_, err := i.client.UntagServerCertificate(createIAMUntagServerCertificateInput(name, tags))
+
+	return err
+}
+
+func (i *IAM) ReplaceTags(name string, tags []Tag) error {
+	return replaceTags(i, name, tags)
+}
+
+func (i *IAM) UpdateTags(action, name string, tags []Tag) (string, error) {
+	return updateTags(i, action, name, tags)
+}
+
+func (i *IAM) BulkUpdateTags(action string, names []string, tags []Tag, dryRun bool) ([]string, error) {
+	return bulkUpdateTags(i, action, names, tags, dryRun)
+}
+
+func (i *IAM) ListTagDescriptions(names []string) ([]TagDescription, error) {
+	return listTagDescriptions(i, names)
+}
+
 func (i *IAM) ReadableList(descs []IAMDescription) {
 	table := tablewriter.NewWriter(os.Stdout)
 
-	table.SetHeader([]string{"Name", "ID", "Path", "Arn"})
+	table.SetHeader([]string{"Name", "ID", "Path", "Upload Date", "Expiration", "Arn"})
 
 	for _, desc := range descs {
-		table.Append([]string{desc.name, desc.id, desc.path, desc.arn})
+		table.Append([]string{desc.name, desc.id, desc.path, desc.uploadDate.String(), desc.expiration.String(), desc.arn})
 	}
 
 	table.Render()
@@ -0,0 +1,138 @@
+package certutils
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	icsTimeFormat   = "20060102T150405Z"
+	icsMaxLineOctet = 75
+)
+
+func icsEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+func icsFold(line string) string {
+	if len(line) <= icsMaxLineOctet {
+		return line
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range line {
+		size := len(string(r))
+		if n+size > icsMaxLineOctet {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+
+	return b.String()
+}
+
+func icsDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("P%dD", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("PT%dH", d/time.Hour)
+	}
+
+	return fmt.Sprintf("PT%dM", d/time.Minute)
+}
+
+func (e ExpiryEntry) summary() string {
+	name := e.cert.ID
+	if len(e.cert.Domains) > 0 {
+		name = e.cert.Domains[0]
+	}
+
+	return fmt.Sprintf("Certificate expires: %s (%s)", name, e.cert.Store)
+}
+
+func (e ExpiryEntry) description() string {
+	lines := []string{
+		fmt.Sprintf("Store: %s", e.cert.Store),
+		fmt.Sprintf("Certificate: %s", e.cert.ID),
+	}
+
+	if e.cert.Arn != e.cert.ID {
+		lines = append(lines, fmt.Sprintf("ARN: %s", e.cert.Arn))
+	}
+
+	if e.cert.NameTag != "" {
+		lines = append(lines, fmt.Sprintf("Name tag: %s", e.cert.NameTag))
+	}
+
+	lines = append(lines, fmt.Sprintf("Domains: %s", strings.Join(e.cert.Domains, " ")))
+
+	if consumers := e.consumers(); len(consumers) > 0 {
+		lines = append(lines, fmt.Sprintf("Attached to: %s", strings.Join(consumers, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (e ExpiryEntry) uid() string {
+	sum := sha1.Sum([]byte(e.cert.Store + e.cert.Arn + e.cert.NotAfter.String()))
+	return fmt.Sprintf("%x@aws-cert-utils", sum)
+}
+
+func WriteExpiryCalendar(w io.Writer, entries []ExpiryEntry, alarms []time.Duration) error {
+	now := time.Now().UTC().Format(icsTimeFormat)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//tkuchiki//aws-cert-utils//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Certificate expiry",
+	}
+
+	for _, e := range entries {
+		notAfter := e.cert.NotAfter.UTC().Format(icsTimeFormat)
+		summary := icsEscape(e.summary())
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+e.uid(),
+			"DTSTAMP:"+now,
+			"DTSTART:"+notAfter,
+			"DTEND:"+notAfter,
+			"SUMMARY:"+summary,
+			"DESCRIPTION:"+icsEscape(e.description()),
+		)
+
+		for _, alarm := range alarms {
+			lines = append(lines,
+				"BEGIN:VALARM",
+				"ACTION:DISPLAY",
+				"DESCRIPTION:"+summary,
+				"TRIGGER:-"+icsDuration(alarm),
+				"END:VALARM",
+			)
+		}
+
+		lines = append(lines, "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		_, err := io.WriteString(w, icsFold(line)+"\r\n")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package certutils
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	stdinSource  = "-"
+	envPrefix    = "env:"
+	fdPrefix     = "fd:"
+	promptSource = "prompt:"
+)
+
+var stdinRead bool
+
+func readStdin() ([]byte, error) {
+	if stdinRead {
+		return []byte{}, fmt.Errorf("stdin can be read only once")
+	}
+	stdinRead = true
+
+	return ioutil.ReadAll(os.Stdin)
+}
+
+func readEnv(name string) ([]byte, error) {
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		return []byte{}, fmt.Errorf("Environment variable %s is not set", name)
+	}
+
+	return []byte(val), nil
+}
+
+func readFd(s string) ([]byte, error) {
+	fd, err := strconv.Atoi(s)
+	if err != nil || fd < 0 {
+		return []byte{}, fmt.Errorf("Invalid file descriptor %s", s)
+	}
+
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return []byte{}, fmt.Errorf("Invalid file descriptor %s", s)
+	}
+	defer f.Close()
+
+	return ioutil.ReadAll(f)
+}
+
+func readPrompt() ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return []byte{}, fmt.Errorf("stdin is not a terminal")
+	}
+
+	fmt.Fprintln(os.Stderr, "Paste the PEM data, ending with the -----END line or Ctrl-D (input is hidden) : ")
+
+	lines := make([]string, 0)
+	for {
+		line, err := term.ReadPassword(fd)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return []byte{}, err
+		}
+
+		s := strings.TrimSpace(string(line))
+		if s == "" && len(lines) == 0 {
+			continue
+		}
+		lines = append(lines, s)
+
+		if strings.HasPrefix(s, "-----END ") {
+			break
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+func readSource(fpath string) ([]byte, error) {
+	switch {
+	case fpath == stdinSource:
+		return readStdin()
+	case fpath == promptSource:
+		return readPrompt()
+	case strings.HasPrefix(fpath, envPrefix):
+		return readEnv(strings.TrimPrefix(fpath, envPrefix))
+	case strings.HasPrefix(fpath, fdPrefix):
+		return readFd(strings.TrimPrefix(fpath, fdPrefix))
+	}
+
+	return readFile(fpath)
+}
@@ -0,0 +1,275 @@
+package certutils
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+const (
+	storeACM = "ACM"
+	storeIAM = "IAM"
+
+	consumerCloudFront = "CloudFront"
+	consumerELB        = "ELB"
+	consumerALB        = "ALB"
+)
+
+type Inventory struct {
+	iam        *IAM
+	cloudFront *CloudFront
+	elb        *ELB
+	alb        *ALB
+	acms       map[string]*ACM
+	region     string
+}
+
+type InventoryCertificate struct {
+	Store       string
+	Region      string
+	ID          string
+	Arn         string
+	IAMID       string
+	NameTag     string
+	Domains     []string
+	NotAfter    time.Time
+	Fingerprint string
+}
+
+type Attachment struct {
+	Consumer string
+	Region   string
+	Name     string
+	Endpoint string
+	Cert     string
+}
+
+func NewInventory(globalSess, regionalSess *session.Session) *Inventory {
+	region := aws.StringValue(regionalSess.Config.Region)
+	if region == "" {
+		region = cloudFrontRegion
+	}
+
+	return &Inventory{
+		iam:        NewIAM(globalSess),
+		cloudFront: NewCloudFront(globalSess, "", 0),
+		elb:        NewELB(regionalSess),
+		alb:        NewALB(regionalSess),
+		acms: map[string]*ACM{
+			cloudFrontRegion: NewACM(globalSess),
+			region:           NewACM(regionalSess),
+		},
+		region: region,
+	}
+}
+
+func (inv *Inventory) acmCertificates(region string, fetchBodies bool) ([]InventoryCertificate, error) {
+	descs, err := inv.acms[region].List("all", "all", 0, "")
+	if err != nil {
+		return []InventoryCertificate{}, err
+	}
+
+	certs := make([]InventoryCertificate, 0, len(descs))
+	for _, desc := range descs {
+		c := InventoryCertificate{
+			Store:    storeACM,
+			Region:   region,
+			ID:       desc.arn,
+			Arn:      desc.arn,
+			NameTag:  desc.nameTag,
+			Domains:  desc.names(),
+			NotAfter: desc.notAfter,
+		}
+
+		if fetchBodies && !desc.notAfter.IsZero() {
+			body, _, err := inv.acms[region].Get(desc.arn)
+			if err == nil {
+				c.Fingerprint, _ = certificateFingerprint(body)
+			}
+		}
+
+		certs = append(certs, c)
+	}
+
+	return certs, nil
+}
+
+func (inv *Inventory) iamCertificates(fetchBodies bool) ([]InventoryCertificate, error) {
+	descs, err := inv.iam.ListAll("")
+	if err != nil {
+		return []InventoryCertificate{}, err
+	}
+
+	certs := make([]InventoryCertificate, 0, len(descs))
+	for _, desc := range descs {
+		c := InventoryCertificate{
+			Store:    storeIAM,
+			ID:       desc.name,
+			Arn:      desc.arn,
+			IAMID:    desc.id,
+			Domains:  []string{},
+			NotAfter: desc.expiration,
+		}
+
+		if fetchBodies {
+			body, _, err := inv.iam.Get(desc.name)
+			if err != nil {
+				return []InventoryCertificate{}, err
+			}
+
+			cert, err := ParseCertificate(body)
+			if err == nil {
+				c.Fingerprint = SHA256Fingerprint(cert)
+				c.Domains = certificateNames(cert)
+			}
+		}
+
+		certs = append(certs, c)
+	}
+
+	return certs, nil
+}
+
+func (inv *Inventory) Certificates(fetchBodies bool) ([]InventoryCertificate, error) {
+	certs := make([]InventoryCertificate, 0)
+	for _, region := range inv.regions() {
+		acmCerts, err := inv.acmCertificates(region, fetchBodies)
+		if err != nil {
+			return []InventoryCertificate{}, err
+		}
+		certs = append(certs, acmCerts...)
+	}
+
+	iamCerts, err := inv.iamCertificates(fetchBodies)
+	if err != nil {
+		return []InventoryCertificate{}, err
+	}
+
+	return append(certs, iamCerts...), nil
+}
+
+func (inv *Inventory) fillDomains(c *InventoryCertificate) error {
+	if c.Store != storeIAM || len(c.Domains) > 0 {
+		return nil
+	}
+
+	body, _, err := inv.iam.Get(c.ID)
+	if err != nil {
+		return err
+	}
+
+	cert, err := ParseCertificate(body)
+	if err == nil {
+		c.Domains = certificateNames(cert)
+	}
+
+	return nil
+}
+
+func (inv *Inventory) regions() []string {
+	if inv.region == cloudFrontRegion {
+		return []string{cloudFrontRegion}
+	}
+
+	return []string{cloudFrontRegion, inv.region}
+}
+
+func (inv *Inventory) Attachments() ([]Attachment, error) {
+	attachments := make([]Attachment, 0)
+
+	dists, err := inv.cloudFront.getDistributions("", "")
+	if err != nil {
+		return []Attachment{}, err
+	}
+
+	for _, dist := range dists {
+		attachments = append(attachments, Attachment{
+			Consumer: consumerCloudFront,
+			Region:   cloudFrontRegion,
+			Name:     dist.id,
+			Endpoint: dist.domain,
+			Cert:     dist.certID,
+		})
+	}
+
+	elbs, err := inv.elb.getDescriptions("", "")
+	if err != nil {
+		return []Attachment{}, err
+	}
+
+	for _, desc := range elbs {
+		for _, cert := range desc.certs {
+			attachments = append(attachments, Attachment{
+				Consumer: consumerELB,
+				Region:   inv.region,
+				Name:     desc.name,
+				Endpoint: fmt.Sprintf("%s:%d", desc.dnsname, cert.port),
+				Cert:     cert.arn,
+			})
+		}
+	}
+
+	albs, err := inv.alb.getLBs("")
+	if err != nil {
+		return []Attachment{}, err
+	}
+
+	for _, desc := range albs {
+		for _, cert := range desc.certs {
+			attachments = append(attachments, Attachment{
+				Consumer: consumerALB,
+				Region:   inv.region,
+				Name:     desc.name,
+				Endpoint: fmt.Sprintf("%s:%d", desc.dnsname, cert.port),
+				Cert:     cert.arn,
+			})
+		}
+	}
+
+	return attachments, nil
+}
+
+func (c InventoryCertificate) AttachedTo(a Attachment) bool {
+	return a.Cert == c.Arn || (c.IAMID != "" && a.Cert == c.IAMID)
+}
+
+func (c InventoryCertificate) attachments(attachments []Attachment) []Attachment {
+	attached := make([]Attachment, 0)
+	for _, a := range attachments {
+		if c.AttachedTo(a) {
+			attached = append(attached, a)
+		}
+	}
+
+	return attached
+}
+
+func (c InventoryCertificate) sharesDomain(names []string) bool {
+	for _, d := range c.Domains {
+		for _, name := range names {
+			if MatchDomain(d, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func certificateNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, len(cert.DNSNames)+1)
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+
+	for _, name := range cert.DNSNames {
+		if name != cert.Subject.CommonName {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
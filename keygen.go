@@ -0,0 +1,182 @@
+package certutils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type CSRSubject struct {
+	CommonName         string   `yaml:"common_name"`
+	Organization       string   `yaml:"organization"`
+	OrganizationalUnit string   `yaml:"organizational_unit"`
+	Country            string   `yaml:"country"`
+	Province           string   `yaml:"province"`
+	Locality           string   `yaml:"locality"`
+	DNSNames           []string `yaml:"dns_names"`
+}
+
+func DefaultKeyBits(keyType string) int {
+	if strings.ToUpper(keyType) == "ECDSA" {
+		return 256
+	}
+
+	return maxPrivateKeyBitLength
+}
+
+func GeneratePrivateKey(keyType string, bit int) (crypto.Signer, error) {
+	switch strings.ToUpper(keyType) {
+	case "RSA":
+		return rsa.GenerateKey(rand.Reader, bit)
+	case "ECDSA":
+		switch bit {
+		case 256:
+			return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		case 384:
+			return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		}
+		return nil, fmt.Errorf("Invalid ECDSA key size %d. Supported sizes are 256 and 384", bit)
+	}
+
+	return nil, fmt.Errorf("Invalid key type %s. Supported key types are rsa and ecdsa", keyType)
+}
+
+func EncodePrivateKey(key crypto.Signer) ([]byte, error) {
+	return encodePrivateKey(key)
+}
+
+func ParsePrivateKey(data []byte, passphrase *PassphraseSource) (crypto.Signer, error) {
+	normalized, err := NormalizePrivateKey(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(normalized)
+	if block == nil {
+		return nil, fmt.Errorf("Private key not found")
+	}
+
+	key, err := parsePrivateKeyDER(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key")
+	}
+
+	return signer, nil
+}
+
+func LoadCSRSubject(fpath string) (CSRSubject, error) {
+	var subject CSRSubject
+	if fpath == "" {
+		return subject, nil
+	}
+
+	data, err := readSource(fpath)
+	if err != nil {
+		return subject, err
+	}
+
+	err = yaml.Unmarshal(data, &subject)
+
+	return subject, err
+}
+
+func (s CSRSubject) Merge(o CSRSubject) CSRSubject {
+	merge := func(a, b string) string {
+		if b != "" {
+			return b
+		}
+		return a
+	}
+
+	merged := CSRSubject{
+		CommonName:         merge(s.CommonName, o.CommonName),
+		Organization:       merge(s.Organization, o.Organization),
+		OrganizationalUnit: merge(s.OrganizationalUnit, o.OrganizationalUnit),
+		Country:            merge(s.Country, o.Country),
+		Province:           merge(s.Province, o.Province),
+		Locality:           merge(s.Locality, o.Locality),
+		DNSNames:           s.DNSNames,
+	}
+
+	if len(o.DNSNames) > 0 {
+		merged.DNSNames = o.DNSNames
+	}
+
+	return merged
+}
+
+func (s CSRSubject) pkixName() pkix.Name {
+	name := pkix.Name{CommonName: s.CommonName}
+
+	if s.Organization != "" {
+		name.Organization = []string{s.Organization}
+	}
+	if s.OrganizationalUnit != "" {
+		name.OrganizationalUnit = []string{s.OrganizationalUnit}
+	}
+	if s.Country != "" {
+		name.Country = []string{s.Country}
+	}
+	if s.Province != "" {
+		name.Province = []string{s.Province}
+	}
+	if s.Locality != "" {
+		name.Locality = []string{s.Locality}
+	}
+
+	return name
+}
+
+func CreateCSR(key crypto.Signer, subject CSRSubject) ([]byte, error) {
+	if subject.CommonName == "" {
+		return []byte{}, fmt.Errorf("Common name is required")
+	}
+
+	dnsNames := subject.DNSNames
+	if len(dnsNames) == 0 {
+		dnsNames = []string{subject.CommonName}
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  subject.pkixName(),
+		DNSNames: dnsNames,
+	}, key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}), nil
+}
+
+func WritePrivateKey(data []byte, fpath string) error {
+	return writeOutput(data, fpath, 0600)
+}
+
+func WriteCSR(data []byte, fpath string) error {
+	return writeOutput(data, fpath, 0644)
+}
+
+func writeOutput(data []byte, fpath string, perm os.FileMode) error {
+	if fpath == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(fpath, data, perm)
+}
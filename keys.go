@@ -0,0 +1,215 @@
+package certutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/youmark/pkcs8"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type PassphraseSource struct {
+	Env    string
+	File   string
+	cached []byte
+}
+
+func (ps *PassphraseSource) Get() ([]byte, error) {
+	if ps.cached != nil {
+		return ps.cached, nil
+	}
+
+	switch {
+	case ps.Env != "":
+		val, ok := os.LookupEnv(ps.Env)
+		if !ok {
+			return []byte{}, fmt.Errorf("Environment variable %s is not set", ps.Env)
+		}
+		ps.cached = []byte(val)
+	case ps.File != "":
+		data, err := readFile(ps.File)
+		if err != nil {
+			return []byte{}, err
+		}
+		ps.cached = []byte(strings.TrimRight(string(data), "\r\n"))
+	default:
+		ps.cached = []byte(Password("Passphrase : "))
+	}
+
+	return ps.cached, nil
+}
+
+func NormalizeCertificates(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if len(certs) == 0 {
+		return []byte{}, fmt.Errorf("Certificate not found")
+	}
+
+	return encodeCertificates(certs), nil
+}
+
+func encodePrivateKey(key interface{}) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return []byte{}, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+	}
+
+	return []byte{}, fmt.Errorf("unsupported private key")
+}
+
+func parsePrivateKeyDER(der []byte) (interface{}, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("Failed to parse private key")
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+func isEncryptedPrivateKeyDER(der []byte) bool {
+	var info encryptedPrivateKeyInfo
+	rest, err := asn1.Unmarshal(der, &info)
+
+	return err == nil && len(rest) == 0
+}
+
+func parseEncryptedPrivateKeyDER(der []byte, passphrase *PassphraseSource) (interface{}, error) {
+	pass, err := passphrase.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := pkcs8.ParsePKCS8PrivateKey(der, pass)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decrypt private key: %s", err)
+	}
+
+	return key, nil
+}
+
+func NormalizePrivateKey(data []byte, passphrase *PassphraseSource) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	var key interface{}
+	var err error
+
+	block, _ := pem.Decode(data)
+	switch {
+	case block == nil:
+		key, err = parsePrivateKeyDER(data)
+		if err != nil && isEncryptedPrivateKeyDER(data) {
+			key, err = parseEncryptedPrivateKeyDER(data, passphrase)
+		}
+	case block.Type == "ENCRYPTED PRIVATE KEY":
+		key, err = parseEncryptedPrivateKeyDER(block.Bytes, passphrase)
+	case x509.IsEncryptedPEMBlock(block):
+		var pass, der []byte
+		pass, err = passphrase.Get()
+		if err != nil {
+			return []byte{}, err
+		}
+
+		der, err = x509.DecryptPEMBlock(block, pass)
+		if err != nil {
+			return []byte{}, fmt.Errorf("Failed to decrypt private key: %s", err)
+		}
+		key, err = parsePrivateKeyDER(der)
+	default:
+		key, err = parsePrivateKeyDER(block.Bytes)
+	}
+
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return encodePrivateKey(key)
+}
+
+func (cm *CertificateManager) LoadPKCS12(fpath string, passphrase *PassphraseSource) error {
+	data, err := readFile(fpath)
+	if err != nil {
+		return err
+	}
+
+	pass, err := passphrase.Get()
+	if err != nil {
+		return err
+	}
+
+	return cm.loadPKCS12Chain(data, string(pass))
+}
+
+func (cm *CertificateManager) loadPKCS12Chain(data []byte, password string) error {
+	key, cert, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return err
+	}
+
+	return cm.setKeyPair(key, cert, caCerts)
+}
+
+func (cm *CertificateManager) setKeyPair(key interface{}, cert *x509.Certificate, caCerts []*x509.Certificate) error {
+	pkey, err := encodePrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	cm.Pkey = pkey
+	cm.Cert = encodeCertificates([]*x509.Certificate{cert})
+	cm.Chain = encodeCertificates(BuildChain(cert, caCerts))
+
+	return nil
+}
+
+func (cm *CertificateManager) Normalize(passphrase *PassphraseSource) error {
+	var err error
+
+	cm.Cert, err = NormalizeCertificates(cm.Cert)
+	if err != nil {
+		return err
+	}
+
+	cm.Chain, err = NormalizeCertificates(cm.Chain)
+	if err != nil {
+		return err
+	}
+
+	cm.Pkey, err = NormalizePrivateKey(cm.Pkey, passphrase)
+
+	return err
+}
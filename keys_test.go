@@ -0,0 +1,150 @@
+package certutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/youmark/pkcs8"
+)
+
+const (
+	testPassphraseEnv      = "AWS_CERT_UTILS_TEST_PASSPHRASE"
+	testWrongPassphraseEnv = "AWS_CERT_UTILS_TEST_WRONG_PASSPHRASE"
+)
+
+func TestNormalizePrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv(testPassphraseEnv, "secret")
+	defer os.Unsetenv(testPassphraseEnv)
+	os.Setenv(testWrongPassphraseEnv, "wrong")
+	defer os.Unsetenv(testWrongPassphraseEnv)
+
+	pkcs1 := x509.MarshalPKCS1PrivateKey(rsaKey)
+
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := pkcs8.MarshalPrivateKey(ecKey, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", pkcs1, []byte("secret"), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantRSA := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: pkcs1})
+	wantEC := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER})
+
+	tests := []struct {
+		name string
+		data []byte
+		env  string
+		want []byte
+		err  string
+	}{
+		{
+			name: "PKCS#1",
+			data: wantRSA,
+			want: wantRSA,
+		},
+		{
+			name: "PKCS#8",
+			data: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: rsaPKCS8}),
+			want: wantRSA,
+		},
+		{
+			name: "EC",
+			data: wantEC,
+			want: wantEC,
+		},
+		{
+			name: "encrypted PKCS#8",
+			data: pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encrypted}),
+			env:  testPassphraseEnv,
+			want: wantEC,
+		},
+		{
+			name: "legacy encrypted PEM",
+			data: pem.EncodeToMemory(legacy),
+			env:  testPassphraseEnv,
+			want: wantRSA,
+		},
+		{
+			name: "PKCS#1 DER",
+			data: pkcs1,
+			want: wantRSA,
+		},
+		{
+			name: "PKCS#8 DER",
+			data: rsaPKCS8,
+			want: wantRSA,
+		},
+		{
+			name: "EC DER",
+			data: ecDER,
+			want: wantEC,
+		},
+		{
+			name: "encrypted PKCS#8 DER",
+			data: encrypted,
+			env:  testPassphraseEnv,
+			want: wantEC,
+		},
+		{
+			name: "wrong passphrase",
+			data: pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encrypted}),
+			env:  testWrongPassphraseEnv,
+			err:  "Failed to decrypt private key",
+		},
+		{
+			name: "garbage does not ask for a passphrase",
+			data: []byte("not a private key"),
+			env:  "AWS_CERT_UTILS_TEST_UNSET",
+			err:  "Failed to parse private key",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePrivateKey(tt.data, &PassphraseSource{Env: tt.env})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", tt.name, err)
+			continue
+		}
+
+		if string(got) != string(tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
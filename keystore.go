@@ -0,0 +1,229 @@
+package certutils
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"sort"
+
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"gopkg.in/yaml.v2"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var jksMagic = []byte{0xFE, 0xED, 0xFE, 0xED}
+
+type kubernetesSecret struct {
+	Kind       string            `yaml:"kind"`
+	Type       string            `yaml:"type"`
+	Data       map[string]string `yaml:"data"`
+	StringData map[string]string `yaml:"stringData"`
+}
+
+func chooseAlias(aliases []string, alias string) (string, error) {
+	if alias != "" {
+		for _, a := range aliases {
+			if a == alias {
+				return alias, nil
+			}
+		}
+
+		return "", fmt.Errorf("Alias %s not found in the keystore. Available aliases: %v", alias, aliases)
+	}
+
+	switch len(aliases) {
+	case 0:
+		return "", fmt.Errorf("Private key entry not found in the keystore")
+	case 1:
+		return aliases[0], nil
+	}
+
+	sort.Strings(aliases)
+	alias = Choice(aliases, "Choose the keystore alias : ", 20)
+	if alias == "" {
+		return "", fmt.Errorf("Alias is not selected")
+	}
+
+	return alias, nil
+}
+
+func (cm *CertificateManager) loadJKS(data []byte, alias string, passphrase *PassphraseSource) error {
+	pass, err := passphrase.Get()
+	if err != nil {
+		return err
+	}
+
+	ks := keystore.New()
+	err = ks.Load(bytes.NewReader(data), pass)
+	if err != nil {
+		return err
+	}
+
+	aliases := make([]string, 0)
+	for _, a := range ks.Aliases() {
+		if ks.IsPrivateKeyEntry(a) {
+			aliases = append(aliases, a)
+		}
+	}
+
+	alias, err = chooseAlias(aliases, alias)
+	if err != nil {
+		return err
+	}
+
+	entry, err := ks.GetPrivateKeyEntry(alias, pass)
+	if err != nil {
+		return err
+	}
+
+	key, err := parsePrivateKeyDER(entry.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	certs := make([]*x509.Certificate, 0, len(entry.CertificateChain))
+	for _, c := range entry.CertificateChain {
+		cert, err := x509.ParseCertificate(c.Content)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return fmt.Errorf("Certificate not found for alias %s", alias)
+	}
+
+	return cm.setKeyPair(key, certs[0], certs[1:])
+}
+
+func (cm *CertificateManager) loadPKCS12Keystore(data []byte, alias string, passphrase *PassphraseSource) error {
+	pass, err := passphrase.Get()
+	if err != nil {
+		return err
+	}
+
+	blocks, err := pkcs12.ToPEM(data, string(pass))
+	if err != nil {
+		if alias != "" {
+			return err
+		}
+
+		return cm.loadPKCS12Chain(data, string(pass))
+	}
+
+	keys := make(map[string]*pem.Block)
+	aliases := make([]string, 0)
+	certs := make([]*x509.Certificate, 0)
+	for _, block := range blocks {
+		if block.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, cert)
+			continue
+		}
+
+		name := block.Headers["friendlyName"]
+		if _, ok := keys[name]; ok {
+			return fmt.Errorf("Duplicate alias %q in the keystore", name)
+		}
+		keys[name] = block
+		aliases = append(aliases, name)
+	}
+
+	if len(aliases) == 1 && (alias == "" || alias == aliases[0]) {
+		return cm.loadPKCS12Chain(data, string(pass))
+	}
+
+	for _, a := range aliases {
+		if a == "" {
+			return fmt.Errorf("Private key entry without friendlyName in the keystore. Every entry needs a friendlyName to be selected by alias")
+		}
+	}
+
+	alias, err = chooseAlias(aliases, alias)
+	if err != nil {
+		return err
+	}
+
+	key, err := parsePrivateKeyDER(keys[alias].Bytes)
+	if err != nil {
+		return err
+	}
+
+	pkey, err := encodePrivateKey(key)
+	if err != nil {
+		return err
+	}
+	cm.Pkey = pkey
+
+	return cm.setCertificates(certs, fmt.Sprintf("the keystore alias %s", alias))
+}
+
+func (cm *CertificateManager) LoadKeystore(fpath, alias string, passphrase *PassphraseSource) error {
+	data, err := readSource(fpath)
+	if err != nil {
+		return err
+	}
+
+	if bytes.HasPrefix(data, jksMagic) {
+		return cm.loadJKS(data, alias, passphrase)
+	}
+
+	return cm.loadPKCS12Keystore(data, alias, passphrase)
+}
+
+func kubernetesSecretValue(secret kubernetesSecret, key string) ([]byte, error) {
+	if val, ok := secret.StringData[key]; ok {
+		return []byte(val), nil
+	}
+
+	val, ok := secret.Data[key]
+	if !ok {
+		return []byte{}, fmt.Errorf("%s not found in the secret", key)
+	}
+
+	return base64.StdEncoding.DecodeString(val)
+}
+
+func (cm *CertificateManager) LoadKubernetesSecret(fpath string) error {
+	data, err := readSource(fpath)
+	if err != nil {
+		return err
+	}
+
+	var secret kubernetesSecret
+	err = yaml.Unmarshal(data, &secret)
+	if err != nil {
+		return err
+	}
+
+	if secret.Kind != "Secret" {
+		return fmt.Errorf("Invalid kind %s. Kubernetes Secret is required", secret.Kind)
+	}
+
+	if secret.Type != "" && secret.Type != "kubernetes.io/tls" {
+		return fmt.Errorf("Invalid secret type %s. kubernetes.io/tls is required", secret.Type)
+	}
+
+	crt, err := kubernetesSecretValue(secret, "tls.crt")
+	if err != nil {
+		return err
+	}
+
+	cm.Pkey, err = kubernetesSecretValue(secret, "tls.key")
+	if err != nil {
+		return err
+	}
+
+	certs, err := ParseCertificates(crt)
+	if err != nil {
+		return err
+	}
+
+	return cm.setCertificates(certs, fpath)
+}
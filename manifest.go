@@ -0,0 +1,269 @@
+package certutils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	manifestOK     = "OK"
+	manifestFailed = "FAILED"
+	manifestNotRun = "NOT RUN"
+)
+
+type Manifest struct {
+	Certificates []ManifestEntry `yaml:"certificates"`
+}
+
+type ManifestEntry struct {
+	Name           string            `yaml:"name"`
+	CertPath       string            `yaml:"cert_path"`
+	ChainPath      string            `yaml:"chain_path"`
+	PkeyPath       string            `yaml:"pkey_path"`
+	BundlePath     string            `yaml:"bundle_path"`
+	PKCS12Path     string            `yaml:"pkcs12_path"`
+	KeystorePath   string            `yaml:"keystore_path"`
+	KeystoreAlias  string            `yaml:"keystore_alias"`
+	K8sSecretPath  string            `yaml:"k8s_secret_path"`
+	PassphraseEnv  string            `yaml:"passphrase_env"`
+	PassphraseFile string            `yaml:"passphrase_file"`
+	Path           string            `yaml:"path"`
+	For            string            `yaml:"for"`
+	Tags           map[string]string `yaml:"tags"`
+}
+
+type ManifestOptions struct {
+	CABundle               []byte
+	OnDuplicate            string
+	ContinueOnError        bool
+	RefuseInlinePrivateKey bool
+}
+
+type ManifestResult struct {
+	name   string
+	status string
+	msg    string
+}
+
+type manifestItem struct {
+	entry ManifestEntry
+	cm    *CertificateManager
+	tags  []Tag
+	path  string
+	err   error
+}
+
+func resolveManifestPath(dir, fpath string) string {
+	if fpath == "" || fpath == stdinSource || fpath == promptSource ||
+		strings.HasPrefix(fpath, envPrefix) || strings.HasPrefix(fpath, fdPrefix) || filepath.IsAbs(fpath) {
+		return fpath
+	}
+
+	return filepath.Join(dir, fpath)
+}
+
+func LoadManifest(fpath string) (Manifest, error) {
+	var m Manifest
+
+	data, err := readFile(fpath)
+	if err != nil {
+		return m, err
+	}
+
+	err = yaml.Unmarshal(data, &m)
+	if err != nil {
+		return m, err
+	}
+
+	if len(m.Certificates) == 0 {
+		return m, fmt.Errorf("No certificates in %s", fpath)
+	}
+
+	dir := filepath.Dir(fpath)
+	for i, e := range m.Certificates {
+		m.Certificates[i].CertPath = resolveManifestPath(dir, e.CertPath)
+		m.Certificates[i].ChainPath = resolveManifestPath(dir, e.ChainPath)
+		m.Certificates[i].PkeyPath = resolveManifestPath(dir, e.PkeyPath)
+		m.Certificates[i].BundlePath = resolveManifestPath(dir, e.BundlePath)
+		m.Certificates[i].PKCS12Path = resolveManifestPath(dir, e.PKCS12Path)
+		m.Certificates[i].KeystorePath = resolveManifestPath(dir, e.KeystorePath)
+		m.Certificates[i].K8sSecretPath = resolveManifestPath(dir, e.K8sSecretPath)
+		m.Certificates[i].PassphraseFile = resolveManifestPath(dir, e.PassphraseFile)
+	}
+
+	return m, nil
+}
+
+func (e ManifestEntry) tags() []Tag {
+	keys := make([]string, 0, len(e.Tags))
+	for key := range e.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := make([]Tag, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, Tag{Key: key, Value: e.Tags[key]})
+	}
+
+	return tags
+}
+
+func (e ManifestEntry) source() CertificateSource {
+	return CertificateSource{
+		CertPath:      e.CertPath,
+		ChainPath:     e.ChainPath,
+		PkeyPath:      e.PkeyPath,
+		BundlePath:    e.BundlePath,
+		PKCS12Path:    e.PKCS12Path,
+		KeystorePath:  e.KeystorePath,
+		KeystoreAlias: e.KeystoreAlias,
+		K8sSecretPath: e.K8sSecretPath,
+		Passphrase: &PassphraseSource{
+			Env:  e.PassphraseEnv,
+			File: e.PassphraseFile,
+		},
+	}
+}
+
+func prepareManifestItem(e ManifestEntry, opts ManifestOptions, service string) manifestItem {
+	item := manifestItem{entry: e, tags: e.tags()}
+	if service == "acm" && e.Name != "" {
+		item.tags = append([]Tag{Tag{Key: "Name", Value: e.Name}}, item.tags...)
+	}
+
+	item.err = CheckTags(item.tags)
+	if item.err != nil {
+		return item
+	}
+
+	item.cm = NewCertificateManager()
+	item.cm.RefuseInlinePrivateKey = opts.RefuseInlinePrivateKey
+
+	item.err = item.cm.Load(e.source())
+	if item.err != nil {
+		return item
+	}
+
+	item.err = item.cm.CheckPrivateKeyBitLen(service)
+	if item.err != nil {
+		return item
+	}
+
+	item.err = item.cm.VerifyChain(opts.CABundle)
+
+	return item
+}
+
+func runManifest(items []manifestItem, opts ManifestOptions, run func(item manifestItem) (string, error)) []ManifestResult {
+	results := make([]ManifestResult, 0, len(items))
+
+	valid := true
+	for _, item := range items {
+		if item.err != nil {
+			valid = false
+		}
+	}
+
+	stopped := !valid && !opts.ContinueOnError
+	for _, item := range items {
+		result := ManifestResult{name: item.entry.Name}
+
+		switch {
+		case item.err != nil:
+			result.status, result.msg = manifestFailed, item.err.Error()
+		case stopped:
+			result.status = manifestNotRun
+		default:
+			msg, err := run(item)
+			if err != nil {
+				result.status, result.msg = manifestFailed, err.Error()
+				stopped = !opts.ContinueOnError
+			} else {
+				result.status, result.msg = manifestOK, msg
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func (a *ACM) ImportManifest(m Manifest, opts ManifestOptions) []ManifestResult {
+	items := make([]manifestItem, 0, len(m.Certificates))
+	for _, e := range m.Certificates {
+		items = append(items, prepareManifestItem(e, opts, "acm"))
+	}
+
+	return runManifest(items, opts, func(item manifestItem) (string, error) {
+		arn, msg, changed, err := a.ImportWithPolicy(opts.OnDuplicate, item.cm.Cert, item.cm.Chain, item.cm.Pkey)
+		if err != nil {
+			return "", err
+		}
+
+		if changed && len(item.tags) > 0 {
+			err = a.AddTags(arn, item.tags)
+			if err != nil {
+				return "", fmt.Errorf("%s, but failed to add the tags to %s: %s", msg, arn, err)
+			}
+		}
+
+		return msg, nil
+	})
+}
+
+func (i *IAM) UploadManifest(m Manifest, opts ManifestOptions) []ManifestResult {
+	items := make([]manifestItem, 0, len(m.Certificates))
+	for _, e := range m.Certificates {
+		item := prepareManifestItem(e, opts, "iam")
+		if item.err == nil && e.Name == "" {
+			item.err = fmt.Errorf("Name is required")
+		}
+		if item.err == nil {
+			path := e.Path
+			if path == "" {
+				path = "/"
+			}
+			item.path, item.err = IAMPathFor(e.For, path)
+		}
+		items = append(items, item)
+	}
+
+	return runManifest(items, opts, func(item manifestItem) (string, error) {
+		return i.UploadWithPolicy(opts.OnDuplicate, item.cm.Cert, item.cm.Chain, item.cm.Pkey, item.path, item.entry.Name, item.tags)
+	})
+}
+
+func ManifestFailed(results []ManifestResult) bool {
+	for _, r := range results {
+		if r.status != manifestOK {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ReadableManifestResults(results []ManifestResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+
+	table.SetHeader([]string{"#", "Name", "Status", "Result"})
+	table.SetAutoWrapText(false)
+
+	counts := map[string]int{}
+	for n, r := range results {
+		counts[r.status]++
+		table.Append([]string{fmt.Sprint(n + 1), r.name, r.status, r.msg})
+	}
+
+	table.Render()
+
+	fmt.Printf("%d succeeded, %d failed, %d not run\n", counts[manifestOK], counts[manifestFailed], counts[manifestNotRun])
+}
@@ -0,0 +1,169 @@
+package certutils
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsExporter struct {
+	inv         *Inventory
+	interval    time.Duration
+	registry    *prometheus.Registry
+	inventory   *inventoryCollector
+	lastRefresh prometheus.Gauge
+	errors      prometheus.Counter
+}
+
+type inventoryCollector struct {
+	mu         sync.RWMutex
+	metrics    []prometheus.Metric
+	expiry     *prometheus.Desc
+	attachment *prometheus.Desc
+}
+
+func (c *inventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.expiry
+	ch <- c.attachment
+}
+
+func (c *inventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+func (c *inventoryCollector) set(metrics []prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.metrics = metrics
+}
+
+func NewMetricsExporter(inv *Inventory, interval time.Duration) *MetricsExporter {
+	m := &MetricsExporter{
+		inv:      inv,
+		interval: interval,
+		registry: prometheus.NewRegistry(),
+		inventory: &inventoryCollector{
+			metrics: make([]prometheus.Metric, 0),
+			expiry: prometheus.NewDesc("cert_expiry_seconds",
+				"The expiration time of the certificate in Unix time.",
+				[]string{"store", "region", "arn", "name_tag", "domain"}, nil),
+			attachment: prometheus.NewDesc("cert_attachment_info",
+				"The certificate attached to a CloudFront distribution, an ELB or an ALB listener.",
+				[]string{"consumer", "region", "name", "endpoint", "cert"}, nil),
+		},
+		lastRefresh: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cert_inventory_last_refresh_timestamp_seconds",
+			Help: "The last time the inventory was refreshed successfully in Unix time.",
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "cert_inventory_refresh_errors_total",
+			Help: "The number of failed inventory refreshes.",
+		}),
+	}
+
+	m.registry.MustRegister(m.inventory, m.lastRefresh, m.errors)
+
+	return m
+}
+
+func (m *MetricsExporter) Refresh() error {
+	certs, err := m.inv.Certificates(false)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := m.inv.Attachments()
+	if err != nil {
+		return err
+	}
+
+	for i := range certs {
+		err = m.inv.fillDomains(&certs[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	metrics := make([]prometheus.Metric, 0, len(certs)+len(attachments))
+	seen := make(map[string]bool)
+	add := func(desc *prometheus.Desc, value float64, labels ...string) error {
+		key := desc.String() + strings.Join(labels, "\x00")
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+
+		metric, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+		if err != nil {
+			return err
+		}
+		metrics = append(metrics, metric)
+
+		return nil
+	}
+
+	for _, c := range certs {
+		if c.NotAfter.IsZero() {
+			continue
+		}
+
+		domain := ""
+		if len(c.Domains) > 0 {
+			domain = c.Domains[0]
+		}
+
+		err = add(m.inventory.expiry, float64(c.NotAfter.Unix()), c.Store, c.Region, c.Arn, c.NameTag, domain)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range attachments {
+		err = add(m.inventory.attachment, 1, a.Consumer, a.Region, a.Name, a.Endpoint, a.Cert)
+		if err != nil {
+			return err
+		}
+	}
+
+	m.inventory.set(metrics)
+	m.lastRefresh.SetToCurrentTime()
+
+	return nil
+}
+
+func (m *MetricsExporter) refreshLoop() {
+	for {
+		time.Sleep(m.interval)
+
+		err := m.Refresh()
+		if err != nil {
+			m.errors.Inc()
+			log.Println(err)
+		}
+	}
+}
+
+func (m *MetricsExporter) Serve(addr string) error {
+	err := m.Refresh()
+	if err != nil {
+		return err
+	}
+
+	go m.refreshLoop()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+
+	return http.ListenAndServe(addr, mux)
+}
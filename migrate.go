@@ -0,0 +1,190 @@
+package certutils
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+const cloudFrontRegion = "us-east-1"
+
+type IAMToACMMigration struct {
+	iam        *IAM
+	cloudFront *CloudFront
+	elb        *ELB
+	alb        *ALB
+	acms       map[string]*ACM
+	region     string
+}
+
+func NewIAMToACMMigration(globalSess, regionalSess *session.Session) *IAMToACMMigration {
+	region := aws.StringValue(regionalSess.Config.Region)
+	if region == "" {
+		region = cloudFrontRegion
+	}
+
+	return &IAMToACMMigration{
+		iam:        NewIAM(globalSess),
+		cloudFront: NewCloudFront(globalSess, "", 0),
+		elb:        NewELB(regionalSess),
+		alb:        NewALB(regionalSess),
+		acms: map[string]*ACM{
+			cloudFrontRegion: NewACM(globalSess),
+			region:           NewACM(regionalSess),
+		},
+		region: region,
+	}
+}
+
+func migrateTags(name string, tags []Tag) []Tag {
+	if getNameTag(tags) != "" {
+		return tags
+	}
+
+	return append([]Tag{Tag{Key: "Name", Value: name}}, tags...)
+}
+
+func pendingACMArn(region string) string {
+	return fmt.Sprintf("(new ACM certificate in %s)", region)
+}
+
+func (m *IAMToACMMigration) Migrate(name string, pkey []byte, deleteIAM, dryRun bool) ([]string, error) {
+	desc, err := m.iam.Describe(name)
+	if err != nil {
+		return []string{}, err
+	}
+
+	cert, chain, err := m.iam.Get(name)
+	if err != nil {
+		return []string{}, err
+	}
+
+	cm := &CertificateManager{Cert: cert, Pkey: pkey}
+	err = cm.CheckPrivateKeyBitLen("acm")
+	if err != nil {
+		return []string{}, err
+	}
+
+	iamTags, err := m.iam.ListTags(name)
+	if err != nil {
+		return []string{}, err
+	}
+	tags := migrateTags(name, iamTags)
+
+	dists, err := m.cloudFront.getDistributions(desc.id, "")
+	if err != nil {
+		return []string{}, err
+	}
+
+	elbs, err := m.elb.getDescriptions("", desc.arn)
+	if err != nil {
+		return []string{}, err
+	}
+
+	albs, err := m.alb.getLBs(desc.arn)
+	if err != nil {
+		return []string{}, err
+	}
+
+	regions := make([]string, 0, 2)
+	if len(dists) > 0 {
+		regions = append(regions, cloudFrontRegion)
+	}
+	if len(elbs) > 0 || len(albs) > 0 || len(dists) == 0 {
+		if len(regions) == 0 || m.region != cloudFrontRegion {
+			regions = append(regions, m.region)
+		}
+	}
+
+	msgs := make([]string, 0)
+	if dryRun {
+		msgs = append(msgs, dryRunMsg()...)
+	}
+
+	arns := make(map[string]string, len(regions))
+	for _, region := range regions {
+		if dryRun {
+			arns[region] = pendingACMArn(region)
+			msgs = append(msgs, fmt.Sprintf("Imported %s -> %s", name, arns[region]))
+			continue
+		}
+
+		a := m.acms[region]
+		arn, _, err := a.Import(cert, chain, pkey)
+		if err != nil {
+			return msgs, err
+		}
+		arns[region] = arn
+
+		err = a.AddTags(arn, tags)
+		if err != nil {
+			return msgs, err
+		}
+
+		msgs = append(msgs, fmt.Sprintf("Imported %s -> %s", name, arn))
+	}
+
+	for _, dist := range dists {
+		arn := arns[cloudFrontRegion]
+		if !dryRun {
+			_, err = m.cloudFront.Update(dist.id, "acm", arn)
+			if err != nil {
+				return msgs, err
+			}
+		}
+		msgs = append(msgs, cfUpdateMsg(dist.id, dist.aliasesStr, desc.id, arn))
+	}
+
+	for _, lb := range elbs {
+		arn := arns[m.region]
+		for _, c := range lb.certs {
+			if !dryRun {
+				_, err = m.elb.client.SetLoadBalancerListenerSSLCertificate(createELBSetLoadBalancerListenerSSLCertificateInput(lb.name, c.port, arn))
+				if err != nil {
+					return msgs, err
+				}
+			}
+			msgs = append(msgs, elbUpdateMsg(lb.name, c.port, desc.arn, arn))
+		}
+	}
+
+	for _, lb := range albs {
+		arn := arns[m.region]
+		for _, c := range lb.certs {
+			if !dryRun {
+				_, err = m.alb.client.ModifyListener(createALBModifyListenerInput(c.listenerArn, arn))
+				if err != nil {
+					return msgs, err
+				}
+			}
+			msgs = append(msgs, albUpdateMsg(lb.name, c.port, desc.arn, arn))
+		}
+	}
+
+	if !deleteIAM {
+		return msgs, nil
+	}
+
+	for _, dist := range dists {
+		if !dryRun {
+			err = m.cloudFront.WaitDeployed(dist.id)
+			if err != nil {
+				return msgs, err
+			}
+		}
+		msgs = append(msgs, fmt.Sprintf("Deployed %s", dist.id))
+	}
+
+	if dryRun {
+		msgs = append(msgs, fmt.Sprintf("Deleted %s", name))
+		return msgs, nil
+	}
+
+	msg, err := m.iam.Delete(name)
+	if err != nil {
+		return msgs, err
+	}
+
+	return append(msgs, msg), nil
+}
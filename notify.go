@@ -0,0 +1,396 @@
+package certutils
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	EventExpiring       = "expiring"
+	EventRotated        = "rotated"
+	EventRotationFailed = "rotation_failed"
+	EventDeleted        = "deleted"
+)
+
+var notificationTimeout = 30 * time.Second
+
+var defaultNotificationTemplates = map[string]string{
+	EventExpiring:       `[{{.Level}}] {{.Store}} certificate {{.Certificate}}{{if .Domains}} ({{join .Domains " "}}){{end}} expires in {{.Days}} day(s) at {{.NotAfter}}{{if .Consumers}}. Attached to {{join .Consumers ", "}}{{end}}`,
+	EventRotated:        `Rotated certificates{{if .Certificate}} for {{.Certificate}}{{end}}:{{"\n"}}{{.Message}}`,
+	EventRotationFailed: `Certificate rotation failed{{if .Certificate}} for {{.Certificate}}{{end}}: {{.Error}}{{if .Message}}{{"\n"}}{{.Message}}{{end}}`,
+	EventDeleted:        `Deleted {{.Store}} certificate {{.Certificate}}`,
+}
+
+type NotificationEvent struct {
+	Type        string
+	Level       string
+	Store       string
+	Certificate string
+	Domains     []string
+	Days        int
+	NotAfter    time.Time
+	Consumers   []string
+	Message     string
+	Error       string
+}
+
+type NotificationConfig struct {
+	Sinks     []NotificationSinkConfig `yaml:"sinks"`
+	Templates map[string]string        `yaml:"templates"`
+}
+
+type NotificationSinkConfig struct {
+	Type        string   `yaml:"type"`
+	Events      []string `yaml:"events"`
+	URL         string   `yaml:"url"`
+	Address     string   `yaml:"address"`
+	Username    string   `yaml:"username"`
+	PasswordEnv string   `yaml:"password_env"`
+	From        string   `yaml:"from"`
+	To          []string `yaml:"to"`
+	TopicArn    string   `yaml:"topic_arn"`
+}
+
+type notificationSink interface {
+	send(subject, body string) error
+}
+
+type filteredSink struct {
+	sink   notificationSink
+	events []string
+}
+
+type Notifier struct {
+	sinks     []filteredSink
+	templates map[string]*template.Template
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+type smtpSink struct {
+	address  string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+type snsSink struct {
+	client   *sns.SNS
+	topicArn string
+}
+
+func (s *webhookSink) send(subject, body string) error {
+	data, err := json.Marshal(map[string]string{"text": body})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook %s returned %s", s.url, resp.Status)
+	}
+
+	return nil
+}
+
+func (s *smtpSink) send(subject, body string) error {
+	host, _, err := net.SplitHostPort(s.address)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", s.address, notificationTimeout)
+	if err != nil {
+		return err
+	}
+
+	err = conn.SetDeadline(time.Now().Add(notificationTimeout))
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.username != "" {
+		err = c.Auth(smtp.PlainAuth("", s.username, s.password, host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.Mail(s.from)
+	if err != nil {
+		return err
+	}
+
+	for _, to := range s.to {
+		err = c.Rcpt(to)
+		if err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		s.from, strings.Join(s.to, ", "), subject, strings.Replace(body, "\n", "\r\n", -1))
+
+	_, err = w.Write([]byte(msg))
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func (s *snsSink) send(subject, body string) error {
+	_, err := s.client.Publish(&sns.PublishInput{
+		TopicArn: aws.String(s.topicArn),
+		Subject:  aws.String(subject),
+		Message:  aws.String(body),
+	})
+
+	return err
+}
+
+func snsTopicRegion(arn string) (string, error) {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 6 || parts[0] != "arn" || parts[2] != "sns" || parts[3] == "" {
+		return "", fmt.Errorf("Invalid SNS topic ARN %s", arn)
+	}
+
+	return parts[3], nil
+}
+
+func newNotificationSink(conf NotificationSinkConfig, newSession func(region string) (*session.Session, error)) (notificationSink, error) {
+	switch conf.Type {
+	case "webhook":
+		if conf.URL == "" {
+			return nil, fmt.Errorf("url is required for the webhook sink")
+		}
+
+		return &webhookSink{url: conf.URL, client: &http.Client{Timeout: notificationTimeout}}, nil
+	case "smtp":
+		if conf.Address == "" || conf.From == "" || len(conf.To) == 0 {
+			return nil, fmt.Errorf("address, from and to are required for the smtp sink")
+		}
+
+		return &smtpSink{
+			address:  conf.Address,
+			username: conf.Username,
+			password: os.Getenv(conf.PasswordEnv),
+			from:     conf.From,
+			to:       conf.To,
+		}, nil
+	case "sns":
+		region, err := snsTopicRegion(conf.TopicArn)
+		if err != nil {
+			return nil, err
+		}
+
+		sess, err := newSession(region)
+		if err != nil {
+			return nil, err
+		}
+
+		return &snsSink{client: sns.New(sess), topicArn: conf.TopicArn}, nil
+	}
+
+	return nil, fmt.Errorf("Invalid sink type %s. Supported types are webhook, smtp and sns", conf.Type)
+}
+
+func LoadNotifier(fpath string, newSession func(region string) (*session.Session, error)) (*Notifier, error) {
+	if fpath == "" {
+		return nil, nil
+	}
+
+	data, err := readFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	var conf NotificationConfig
+	err = yaml.Unmarshal(data, &conf)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Notifier{
+		sinks:     make([]filteredSink, 0, len(conf.Sinks)),
+		templates: make(map[string]*template.Template),
+	}
+
+	funcs := template.FuncMap{"join": strings.Join}
+	for event, text := range defaultNotificationTemplates {
+		if t, ok := conf.Templates[event]; ok {
+			text = t
+		}
+
+		n.templates[event], err = template.New(event).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid template %s: %s", event, err)
+		}
+	}
+
+	for event := range conf.Templates {
+		if _, ok := defaultNotificationTemplates[event]; !ok {
+			return nil, fmt.Errorf("Invalid event %s. Supported events are expiring, rotated, rotation_failed and deleted", event)
+		}
+	}
+
+	for _, sc := range conf.Sinks {
+		for _, event := range sc.Events {
+			if _, ok := defaultNotificationTemplates[event]; !ok {
+				return nil, fmt.Errorf("Invalid event %s. Supported events are expiring, rotated, rotation_failed and deleted", event)
+			}
+		}
+
+		sink, err := newNotificationSink(sc, newSession)
+		if err != nil {
+			return nil, err
+		}
+
+		n.sinks = append(n.sinks, filteredSink{sink: sink, events: sc.Events})
+	}
+
+	return n, nil
+}
+
+func (s filteredSink) accepts(event string) bool {
+	if len(s.events) == 0 {
+		return true
+	}
+
+	for _, e := range s.events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *Notifier) Notify(event NotificationEvent) error {
+	if n == nil {
+		return nil
+	}
+
+	t, ok := n.templates[event.Type]
+	if !ok {
+		return fmt.Errorf("Invalid event %s", event.Type)
+	}
+
+	var body bytes.Buffer
+	err := t.Execute(&body, event)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("aws-cert-utils: %s", strings.Replace(event.Type, "_", " ", -1))
+
+	errs := make([]string, 0)
+	for _, s := range n.sinks {
+		if !s.accepts(event.Type) {
+			continue
+		}
+
+		err = s.sink.send(subject, body.String())
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Failed to send notifications: %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (e ExpiryEntry) Event() NotificationEvent {
+	return NotificationEvent{
+		Type:        EventExpiring,
+		Level:       expiryLevelString(e.level),
+		Store:       e.cert.Store,
+		Certificate: e.cert.ID,
+		Domains:     e.cert.Domains,
+		Days:        e.days,
+		NotAfter:    e.cert.NotAfter,
+		Consumers:   e.consumers(),
+	}
+}
+
+func RotatedEvent(cert string, msgs []string, err error) NotificationEvent {
+	lines := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg == "" || strings.HasPrefix(msg, "#") {
+			continue
+		}
+		lines = append(lines, msg)
+	}
+
+	event := NotificationEvent{
+		Type:        EventRotated,
+		Certificate: cert,
+		Message:     strings.Join(lines, "\n"),
+	}
+
+	if err != nil {
+		event.Type = EventRotationFailed
+		event.Error = err.Error()
+	}
+
+	return event
+}
+
+func DeletedEvent(store, cert string) NotificationEvent {
+	return NotificationEvent{
+		Type:        EventDeleted,
+		Store:       store,
+		Certificate: cert,
+	}
+}
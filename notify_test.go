@@ -0,0 +1,245 @@
+package certutils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type webhookRecorder struct {
+	mu     sync.Mutex
+	bodies map[string][]string
+}
+
+func (r *webhookRecorder) handler(w http.ResponseWriter, req *http.Request) {
+	var payload map[string]string
+	err := json.NewDecoder(req.Body).Decode(&payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r.mu.Lock()
+	r.bodies[req.URL.Path] = append(r.bodies[req.URL.Path], payload["text"])
+	r.mu.Unlock()
+}
+
+func (r *webhookRecorder) get(path string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.bodies[path]
+}
+
+func loadTestNotifier(t *testing.T, config string) *Notifier {
+	dir, err := ioutil.TempDir("", "notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fpath := filepath.Join(dir, "notify.yaml")
+	err = ioutil.WriteFile(fpath, []byte(config), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := LoadNotifier(fpath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return n
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	rec := &webhookRecorder{bodies: make(map[string][]string)}
+	srv := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer srv.Close()
+
+	n := loadTestNotifier(t, fmt.Sprintf(`
+sinks:
+  - type: webhook
+    url: %s/expiring
+    events: [expiring]
+  - type: webhook
+    url: %s/rotation
+    events: [rotated, rotation_failed]
+  - type: webhook
+    url: %s/all
+templates:
+  expiring: "{{.Level}} {{.Certificate}} {{.Days}} {{join .Domains \",\"}}"
+`, srv.URL, srv.URL, srv.URL))
+
+	events := []NotificationEvent{
+		{Type: EventExpiring, Level: "CRITICAL", Certificate: "www", Days: 3, Domains: []string{"www.example.com", "example.com"}},
+		RotatedEvent("arn:aws:acm:us-east-1:xxxxxxxxxxxx:certificate/new", []string{"# Dry run mode", "", "Updated lb:443 old -> new"}, nil),
+		RotatedEvent("", []string{}, fmt.Errorf("access denied")),
+		DeletedEvent("IAM", "old-cert"),
+	}
+
+	for _, event := range events {
+		err := n.Notify(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path   string
+		bodies []string
+	}{
+		{
+			path:   "/expiring",
+			bodies: []string{"CRITICAL www 3 www.example.com,example.com"},
+		},
+		{
+			path: "/rotation",
+			bodies: []string{
+				"Rotated certificates for arn:aws:acm:us-east-1:xxxxxxxxxxxx:certificate/new:\nUpdated lb:443 old -> new",
+				"Certificate rotation failed: access denied",
+			},
+		},
+		{
+			path: "/all",
+			bodies: []string{
+				"CRITICAL www 3 www.example.com,example.com",
+				"Rotated certificates for arn:aws:acm:us-east-1:xxxxxxxxxxxx:certificate/new:\nUpdated lb:443 old -> new",
+				"Certificate rotation failed: access denied",
+				"Deleted IAM certificate old-cert",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := rec.get(tt.path)
+		if strings.Join(got, "|") != strings.Join(tt.bodies, "|") {
+			t.Errorf("%s: got %q, want %q", tt.path, got, tt.bodies)
+		}
+	}
+}
+
+func TestNotifyWebhookErrors(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	done := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-done
+	}))
+	defer hanging.Close()
+	defer close(done)
+
+	timeout := notificationTimeout
+	notificationTimeout = 100 * time.Millisecond
+	defer func() { notificationTimeout = timeout }()
+
+	n := loadTestNotifier(t, fmt.Sprintf(`
+sinks:
+  - type: webhook
+    url: %s
+  - type: webhook
+    url: %s
+`, failing.URL, hanging.URL))
+
+	start := time.Now()
+	err := n.Notify(DeletedEvent("ACM", "arn"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "503 Service Unavailable") {
+		t.Errorf("expected the status in %q", err)
+	}
+
+	if !strings.Contains(err.Error(), hanging.URL) {
+		t.Errorf("expected the hanging webhook in %q", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify took %s", elapsed)
+	}
+}
+
+func TestLoadNotifierInvalidConfig(t *testing.T) {
+	tests := []string{
+		"templates:\n  unknown: \"{{.Certificate}}\"\n",
+		"templates:\n  expiring: \"{{.Certificate\"\n",
+		"sinks:\n  - type: webhook\n",
+		"sinks:\n  - type: webhook\n    url: http://localhost\n    events: [expire]\n",
+	}
+
+	for _, config := range tests {
+		dir, err := ioutil.TempDir("", "notify")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fpath := filepath.Join(dir, "notify.yaml")
+		err = ioutil.WriteFile(fpath, []byte(config), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = LoadNotifier(fpath, nil)
+		os.RemoveAll(dir)
+		if err == nil {
+			t.Errorf("expected an error for %q", config)
+		}
+	}
+}
+
+func TestNotifySMTPTimeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	conns := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			conns <- conn
+		}
+	}()
+
+	timeout := notificationTimeout
+	notificationTimeout = 100 * time.Millisecond
+	defer func() { notificationTimeout = timeout }()
+
+	n := loadTestNotifier(t, fmt.Sprintf(`
+sinks:
+  - type: smtp
+    address: %s
+    from: certs@example.com
+    to: [ops@example.com]
+`, l.Addr()))
+
+	start := time.Now()
+	err = n.Notify(DeletedEvent("ACM", "arn"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify took %s", elapsed)
+	}
+
+	select {
+	case conn := <-conns:
+		conn.Close()
+	default:
+	}
+}
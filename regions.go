@@ -0,0 +1,343 @@
+package certutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const allRegions = "all"
+
+type RegionalSessions struct {
+	regions  []string
+	sessions map[string]*session.Session
+}
+
+func ParseRegions(sess *session.Session, regions string) ([]string, error) {
+	if regions == allRegions {
+		out, err := ec2.New(sess).DescribeRegions(&ec2.DescribeRegionsInput{})
+		if err != nil {
+			return []string{}, err
+		}
+
+		names := make([]string, 0, len(out.Regions))
+		for _, r := range out.Regions {
+			names = append(names, aws.StringValue(r.RegionName))
+		}
+		sort.Strings(names)
+
+		return names, nil
+	}
+
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, r := range strings.Split(regions, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" || seen[r] {
+			continue
+		}
+		seen[r] = true
+		names = append(names, r)
+	}
+
+	if len(names) == 0 {
+		return []string{}, fmt.Errorf("Specify the regions(all or comma separated, e.g. us-east-1,eu-west-1)")
+	}
+
+	return names, nil
+}
+
+func NewRegionalSessions(regions []string, newSession func(region string) (*session.Session, error)) (*RegionalSessions, error) {
+	rs := &RegionalSessions{
+		regions:  regions,
+		sessions: make(map[string]*session.Session, len(regions)),
+	}
+
+	for _, region := range regions {
+		sess, err := newSession(region)
+		if err != nil {
+			return nil, err
+		}
+		rs.sessions[region] = sess
+	}
+
+	return rs, nil
+}
+
+func (rs *RegionalSessions) Regions() []string {
+	return rs.regions
+}
+
+func (rs *RegionalSessions) each(fn func(region string, sess *session.Session) error) error {
+	errs := make([]error, len(rs.regions))
+
+	var wg sync.WaitGroup
+	for i, region := range rs.regions {
+		wg.Add(1)
+		go func(i int, region string) {
+			defer wg.Done()
+			errs[i] = fn(region, rs.sessions[region])
+		}(i, region)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("%s: %s", rs.regions[i], err)
+		}
+	}
+
+	return nil
+}
+
+func regionMsg(region, msg string) string {
+	if region == "" {
+		return msg
+	}
+
+	return fmt.Sprintf("[%s] %s", region, msg)
+}
+
+func (rs *RegionalSessions) ListACM(statuses, keyTypes string, maxItems int64) ([]ACMDescription, error) {
+	results := make([][]ACMDescription, len(rs.regions))
+	index := rs.index()
+
+	err := rs.each(func(region string, sess *session.Session) error {
+		descs, err := NewACM(sess).List(statuses, keyTypes, maxItems, "")
+		for i := range descs {
+			descs[i].region = region
+		}
+		results[index[region]] = descs
+		return err
+	})
+	if err != nil {
+		return []ACMDescription{}, err
+	}
+
+	descs := make([]ACMDescription, 0)
+	for _, r := range results {
+		descs = append(descs, r...)
+	}
+
+	return descs, nil
+}
+
+func (rs *RegionalSessions) ListELB(certFilter string) ([]ELBDescription, error) {
+	results := make([][]ELBDescription, len(rs.regions))
+	index := rs.index()
+
+	err := rs.each(func(region string, sess *session.Session) error {
+		descs, err := NewELB(sess).List(certFilter)
+		for i := range descs {
+			descs[i].region = region
+		}
+		results[index[region]] = descs
+		return err
+	})
+	if err != nil {
+		return []ELBDescription{}, err
+	}
+
+	descs := make([]ELBDescription, 0)
+	for _, r := range results {
+		descs = append(descs, r...)
+	}
+
+	return descs, nil
+}
+
+func (rs *RegionalSessions) ListALB(certFilter string) ([]ALBDescription, error) {
+	results := make([][]ALBDescription, len(rs.regions))
+	index := rs.index()
+
+	err := rs.each(func(region string, sess *session.Session) error {
+		lbs, err := NewALB(sess).List(certFilter)
+		for i := range lbs {
+			lbs[i].region = region
+		}
+		results[index[region]] = lbs
+		return err
+	})
+	if err != nil {
+		return []ALBDescription{}, err
+	}
+
+	lbs := make([]ALBDescription, 0)
+	for _, r := range results {
+		lbs = append(lbs, r...)
+	}
+
+	return lbs, nil
+}
+
+func (rs *RegionalSessions) index() map[string]int {
+	index := make(map[string]int, len(rs.regions))
+	for i, region := range rs.regions {
+		index[region] = i
+	}
+
+	return index
+}
+
+func RegionCertificateArn(arns []string, region string) (string, error) {
+	found := ""
+	for _, arn := range arns {
+		r, err := ACMArnRegion(arn)
+		if err != nil {
+			r = ""
+		}
+
+		if r != "" && r != region {
+			continue
+		}
+
+		if found != "" {
+			return "", fmt.Errorf("Multiple certificates for %s: %s, %s", region, found, arn)
+		}
+		found = arn
+	}
+
+	return found, nil
+}
+
+type regionalCertPair struct {
+	region string
+	src    string
+	dest   string
+}
+
+func (rs *RegionalSessions) certPairs(srcCertArns, destCertArns []string) ([]regionalCertPair, error) {
+	if len(srcCertArns) == 0 {
+		return []regionalCertPair{}, fmt.Errorf("Specify the source certificate ARN")
+	}
+
+	pairs := make([]regionalCertPair, 0, len(rs.regions))
+	for _, region := range rs.regions {
+		src, err := RegionCertificateArn(srcCertArns, region)
+		if err != nil {
+			return []regionalCertPair{}, err
+		}
+		if src == "" {
+			continue
+		}
+
+		dest, err := RegionCertificateArn(destCertArns, region)
+		if err != nil {
+			return []regionalCertPair{}, err
+		}
+		if dest == "" {
+			return []regionalCertPair{}, fmt.Errorf("Destination certificate for %s not found", region)
+		}
+
+		pairs = append(pairs, regionalCertPair{region: region, src: src, dest: dest})
+	}
+
+	return pairs, nil
+}
+
+func (rs *RegionalSessions) BulkUpdateELB(srcCertArns, destCertArns []string, dryRun bool) ([]string, error) {
+	pairs, err := rs.certPairs(srcCertArns, destCertArns)
+	if err != nil {
+		return []string{}, err
+	}
+
+	elbs := make(map[string]*ELB, len(pairs))
+	results := make(map[string][]ELBDescription, len(pairs))
+	var mu sync.Mutex
+	err = rs.each(func(region string, sess *session.Session) error {
+		for _, p := range pairs {
+			if p.region != region {
+				continue
+			}
+
+			e := NewELB(sess)
+			descs, err := e.getDescriptions("", p.src)
+			for i := range descs {
+				descs[i].region = region
+			}
+
+			mu.Lock()
+			elbs[region] = e
+			results[region] = descs
+			mu.Unlock()
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return []string{}, err
+	}
+
+	updates := make([]string, 0)
+	if dryRun {
+		updates = append(updates, dryRunMsg()...)
+	}
+
+	for _, p := range pairs {
+		msgs, err := elbs[p.region].updateListeners(results[p.region], p.src, p.dest, dryRun)
+		updates = append(updates, msgs...)
+		if err != nil {
+			return updates, fmt.Errorf("%s: %s", p.region, err)
+		}
+	}
+
+	return updates, nil
+}
+
+func (rs *RegionalSessions) BulkUpdateALB(srcCertArns, destCertArns []string, dryRun bool) ([]string, error) {
+	pairs, err := rs.certPairs(srcCertArns, destCertArns)
+	if err != nil {
+		return []string{}, err
+	}
+
+	albs := make(map[string]*ALB, len(pairs))
+	results := make(map[string][]ALBDescription, len(pairs))
+	var mu sync.Mutex
+	err = rs.each(func(region string, sess *session.Session) error {
+		for _, p := range pairs {
+			if p.region != region {
+				continue
+			}
+
+			alb := NewALB(sess)
+			lbs, err := alb.getLBs(p.src)
+			for i := range lbs {
+				lbs[i].region = region
+			}
+
+			mu.Lock()
+			albs[region] = alb
+			results[region] = lbs
+			mu.Unlock()
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return []string{}, err
+	}
+
+	updates := make([]string, 0)
+	if dryRun {
+		updates = append(updates, dryRunMsg()...)
+	}
+
+	for _, p := range pairs {
+		msgs, err := albs[p.region].updateListeners(results[p.region], p.src, p.dest, dryRun)
+		updates = append(updates, msgs...)
+		if err != nil {
+			return updates, fmt.Errorf("%s: %s", p.region, err)
+		}
+	}
+
+	return updates, nil
+}
@@ -0,0 +1,311 @@
+package certutils
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+type DirSync struct {
+	dir        string
+	certFile   string
+	keyFile    string
+	caBundle   []byte
+	acm        *ACM
+	acmArn     string
+	iam        *IAM
+	iamName    string
+	iamPath    string
+	cloudFront *CloudFront
+	elb        *ELB
+	alb        *ALB
+	last       string
+}
+
+func NewACMDirSync(sess *session.Session, dir, certFile, keyFile, arn string, caBundle []byte) *DirSync {
+	return &DirSync{
+		dir:      dir,
+		certFile: certFile,
+		keyFile:  keyFile,
+		caBundle: caBundle,
+		acm:      NewACM(sess),
+		acmArn:   arn,
+	}
+}
+
+func NewIAMDirSync(globalSess, regionalSess *session.Session, dir, certFile, keyFile, name, path string, caBundle []byte) *DirSync {
+	return &DirSync{
+		dir:        dir,
+		certFile:   certFile,
+		keyFile:    keyFile,
+		caBundle:   caBundle,
+		iam:        NewIAM(globalSess),
+		iamName:    name,
+		iamPath:    path,
+		cloudFront: NewCloudFront(globalSess, "", 0),
+		elb:        NewELB(regionalSess),
+		alb:        NewALB(regionalSess),
+	}
+}
+
+func ACMArnRegion(arn string) (string, error) {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 6 || parts[0] != "arn" || parts[2] != "acm" || parts[3] == "" {
+		return "", fmt.Errorf("Invalid ACM certificate ARN %s", arn)
+	}
+
+	return parts[3], nil
+}
+
+func (s *DirSync) load() (*CertificateManager, string, error) {
+	cm := NewCertificateManager()
+
+	err := cm.Load(CertificateSource{
+		BundlePath: filepath.Join(s.dir, s.certFile),
+		PkeyPath:   filepath.Join(s.dir, s.keyFile),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = cm.VerifyChain(s.caBundle)
+	if err != nil {
+		return nil, "", err
+	}
+
+	fp, err := certificateFingerprint(cm.Cert)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return cm, fp, nil
+}
+
+func (s *DirSync) Target() string {
+	if s.acmArn != "" {
+		return s.acmArn
+	}
+
+	return s.iamName
+}
+
+func (s *DirSync) Sync(dryRun bool) ([]string, bool, error) {
+	cm, fp, err := s.load()
+	if err != nil {
+		return []string{}, false, err
+	}
+
+	if fp == s.last {
+		return []string{}, false, nil
+	}
+
+	var msgs []string
+	var changed bool
+	if s.acmArn != "" {
+		msgs, changed, err = s.syncACM(cm, fp, dryRun)
+	} else {
+		msgs, changed, err = s.syncIAM(cm, dryRun)
+	}
+	if err != nil {
+		return msgs, false, err
+	}
+
+	s.last = fp
+
+	return msgs, changed && !dryRun, nil
+}
+
+func (s *DirSync) syncACM(cm *CertificateManager, fp string, dryRun bool) ([]string, bool, error) {
+	body, _, err := s.acm.Get(s.acmArn)
+	if err != nil {
+		return []string{}, false, err
+	}
+
+	deployed, err := certificateFingerprint(body)
+	if err == nil && deployed == fp {
+		return []string{fmt.Sprintf("%s is up to date", s.acmArn)}, false, nil
+	}
+
+	err = cm.CheckPrivateKeyBitLen("acm")
+	if err != nil {
+		return []string{}, false, err
+	}
+
+	if dryRun {
+		return append(dryRunMsg(), fmt.Sprintf("Reimported %s", s.acmArn)), true, nil
+	}
+
+	msg, err := s.acm.Reimport(s.acmArn, cm.Cert, cm.Chain, cm.Pkey)
+	if err != nil {
+		return []string{}, false, err
+	}
+
+	return []string{msg}, true, nil
+}
+
+func (s *DirSync) iamCertificates() ([]IAMDescription, error) {
+	descs, err := s.iam.ListAll(s.iamPath)
+	if err != nil {
+		return []IAMDescription{}, err
+	}
+
+	certs := make([]IAMDescription, 0)
+	for _, desc := range descs {
+		if desc.name != s.iamName && !strings.HasPrefix(desc.name, s.iamName+"-") {
+			continue
+		}
+		certs = append(certs, desc)
+	}
+
+	return certs, nil
+}
+
+func (s *DirSync) syncIAM(cm *CertificateManager, dryRun bool) ([]string, bool, error) {
+	current, found, err := s.iam.FindDuplicate(cm.Cert)
+	if err != nil {
+		return []string{}, false, err
+	}
+
+	certs, err := s.iamCertificates()
+	if err != nil {
+		return []string{}, false, err
+	}
+
+	msgs := make([]string, 0)
+	if dryRun {
+		msgs = append(msgs, dryRunMsg()...)
+	}
+
+	if !found {
+		err = cm.CheckPrivateKeyBitLen("iam")
+		if err != nil {
+			return []string{}, false, err
+		}
+
+		cert, err := ParseCertificate(cm.Cert)
+		if err != nil {
+			return []string{}, false, err
+		}
+		name := fmt.Sprintf("%s-%s", s.iamName, cert.NotBefore.UTC().Format("20060102-150405"))
+
+		if dryRun {
+			current = IAMDescription{
+				name:       name,
+				id:         fmt.Sprintf("(new IAM certificate %s)", name),
+				arn:        fmt.Sprintf("(new IAM certificate %s)", name),
+				uploadDate: time.Now(),
+			}
+			msgs = append(msgs, fmt.Sprintf("Uploaded %s", name))
+		} else {
+			msg, err := s.iam.Upload(cm.Cert, cm.Chain, cm.Pkey, s.iamPath, name, []Tag{})
+			if err != nil {
+				return msgs, true, err
+			}
+			msgs = append(msgs, msg)
+
+			current, err = s.iam.Describe(name)
+			if err != nil {
+				return msgs, true, err
+			}
+		}
+	}
+
+	old := make([]IAMDescription, 0)
+	for _, c := range certs {
+		if c.id != current.id && c.uploadDate.Before(current.uploadDate) {
+			old = append(old, c)
+		}
+	}
+
+	updates, err := s.rotateIAMConsumers(old, current, dryRun)
+	msgs = append(msgs, updates...)
+	if err != nil {
+		return msgs, true, err
+	}
+
+	if found && len(updates) == 0 {
+		return []string{fmt.Sprintf("%s is up to date", current.name)}, false, nil
+	}
+
+	return msgs, true, nil
+}
+
+func (s *DirSync) rotateIAMConsumers(old []IAMDescription, desc IAMDescription, dryRun bool) ([]string, error) {
+	msgs := make([]string, 0)
+	if len(old) == 0 {
+		return msgs, nil
+	}
+
+	ids := make(map[string]bool, len(old))
+	arns := make(map[string]bool, len(old))
+	for _, o := range old {
+		ids[o.id] = true
+		arns[o.arn] = true
+	}
+
+	dists, err := s.cloudFront.getDistributions("", "")
+	if err != nil {
+		return msgs, err
+	}
+
+	for _, dist := range dists {
+		if !ids[dist.certID] {
+			continue
+		}
+
+		if !dryRun {
+			_, err = s.cloudFront.Update(dist.id, "iam", desc.id)
+			if err != nil {
+				return msgs, err
+			}
+		}
+		msgs = append(msgs, cfUpdateMsg(dist.id, dist.aliasesStr, dist.certID, desc.id))
+	}
+
+	elbs, err := s.elb.getDescriptions("", "")
+	if err != nil {
+		return msgs, err
+	}
+
+	for _, lb := range elbs {
+		for _, c := range lb.certs {
+			if !arns[c.arn] {
+				continue
+			}
+
+			if !dryRun {
+				_, err = s.elb.client.SetLoadBalancerListenerSSLCertificate(createELBSetLoadBalancerListenerSSLCertificateInput(lb.name, c.port, desc.arn))
+				if err != nil {
+					return msgs, err
+				}
+			}
+			msgs = append(msgs, elbUpdateMsg(lb.name, c.port, c.arn, desc.arn))
+		}
+	}
+
+	albs, err := s.alb.getLBs("")
+	if err != nil {
+		return msgs, err
+	}
+
+	for _, lb := range albs {
+		for _, c := range lb.certs {
+			if !arns[c.arn] {
+				continue
+			}
+
+			if !dryRun {
+				_, err = s.alb.client.ModifyListener(createALBModifyListenerInput(c.listenerArn, desc.arn))
+				if err != nil {
+					return msgs, err
+				}
+			}
+			msgs = append(msgs, albUpdateMsg(lb.name, c.port, c.arn, desc.arn))
+		}
+	}
+
+	return msgs, nil
+}
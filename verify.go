@@ -0,0 +1,210 @@
+package certutils
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	verifyDeployed = "DEPLOYED"
+	verifyMissing  = "MISSING"
+	verifyOutdated = "OUTDATED"
+)
+
+type LocalCertificate struct {
+	path        string
+	cert        *x509.Certificate
+	fingerprint string
+}
+
+type VerifyResult struct {
+	path     string
+	domain   string
+	status   string
+	store    string
+	cert     string
+	consumer string
+	endpoint string
+}
+
+func loadLocalCertificate(fpath string, skipUnknown bool) (LocalCertificate, bool, error) {
+	data, err := readFile(fpath)
+	if err != nil {
+		return LocalCertificate{}, false, err
+	}
+
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		if skipUnknown && len(pemBlocks(data, "")) == 0 {
+			return LocalCertificate{}, false, nil
+		}
+		return LocalCertificate{}, false, fmt.Errorf("%s: %s", fpath, err)
+	}
+
+	for _, cert := range certs {
+		if cert.IsCA {
+			continue
+		}
+
+		return LocalCertificate{
+			path:        fpath,
+			cert:        cert,
+			fingerprint: SHA256Fingerprint(cert),
+		}, true, nil
+	}
+
+	return LocalCertificate{}, false, nil
+}
+
+func LoadLocalCertificates(fpath string) ([]LocalCertificate, error) {
+	info, err := os.Stat(fpath)
+	if err != nil {
+		return []LocalCertificate{}, err
+	}
+
+	if !info.IsDir() {
+		local, ok, err := loadLocalCertificate(fpath, false)
+		if err != nil {
+			return []LocalCertificate{}, err
+		}
+		if !ok {
+			return []LocalCertificate{}, fmt.Errorf("Certificate not found in %s", fpath)
+		}
+
+		return []LocalCertificate{local}, nil
+	}
+
+	locals := make([]LocalCertificate, 0)
+	seen := make(map[string]bool)
+	err = filepath.Walk(fpath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		local, ok, err := loadLocalCertificate(path, true)
+		if err != nil {
+			return err
+		}
+
+		if ok && !seen[local.fingerprint] {
+			seen[local.fingerprint] = true
+			locals = append(locals, local)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return []LocalCertificate{}, err
+	}
+
+	if len(locals) == 0 {
+		return []LocalCertificate{}, fmt.Errorf("Certificate not found in %s", fpath)
+	}
+
+	return locals, nil
+}
+
+func verifyCertificate(local LocalCertificate, certs []InventoryCertificate, attachments []Attachment) []VerifyResult {
+	names := certificateNames(local.cert)
+	result := VerifyResult{
+		path:   local.path,
+		domain: strings.Join(names, " "),
+	}
+
+	results := make([]VerifyResult, 0)
+	for _, c := range certs {
+		if c.Fingerprint != local.fingerprint {
+			continue
+		}
+
+		attached := c.attachments(attachments)
+		if len(attached) == 0 {
+			r := result
+			r.status, r.store, r.cert = verifyDeployed, c.Store, c.ID
+			results = append(results, r)
+			continue
+		}
+
+		for _, a := range attached {
+			r := result
+			r.status, r.store, r.cert, r.consumer, r.endpoint = verifyDeployed, c.Store, c.ID, a.Consumer, a.Endpoint
+			results = append(results, r)
+		}
+	}
+
+	if len(results) == 0 {
+		r := result
+		r.status = verifyMissing
+		results = append(results, r)
+	}
+
+	for _, c := range certs {
+		if c.Fingerprint == "" || c.Fingerprint == local.fingerprint || !c.NotAfter.Before(local.cert.NotAfter) {
+			continue
+		}
+
+		if !c.sharesDomain(names) {
+			continue
+		}
+
+		for _, a := range c.attachments(attachments) {
+			r := result
+			r.status, r.store, r.cert, r.consumer, r.endpoint = verifyOutdated, c.Store, c.ID, a.Consumer, a.Endpoint
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
+
+func (inv *Inventory) Verify(locals []LocalCertificate) ([]VerifyResult, error) {
+	certs, err := inv.Certificates(true)
+	if err != nil {
+		return []VerifyResult{}, err
+	}
+
+	attachments, err := inv.Attachments()
+	if err != nil {
+		return []VerifyResult{}, err
+	}
+
+	results := make([]VerifyResult, 0, len(locals))
+	for _, local := range locals {
+		results = append(results, verifyCertificate(local, certs, attachments)...)
+	}
+
+	return results, nil
+}
+
+func HasDrift(results []VerifyResult) bool {
+	for _, r := range results {
+		if r.status != verifyDeployed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ReadableVerifyResults(results []VerifyResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+
+	table.SetHeader([]string{"File", "Domain Name", "Status", "Store", "Certificate", "Consumer", "Endpoint"})
+	table.SetAutoMergeCells(true)
+	table.SetRowLine(true)
+
+	for _, r := range results {
+		table.Append([]string{r.path, r.domain, r.status, r.store, r.cert, r.consumer, r.endpoint})
+	}
+
+	table.Render()
+}